@@ -0,0 +1,136 @@
+package talkback
+
+import "context"
+
+// rolesContextKey is the context key for the caller's roles.
+type rolesContextKey struct{}
+
+// WithRoles returns a copy of ctx carrying the caller's roles.
+func WithRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesContextKey{}, roles)
+}
+
+// RolesFromContext returns the caller's roles stored by WithRoles.
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesContextKey{}).([]string)
+
+	return roles
+}
+
+// AccessPolicy restricts which fields of a Query a caller may use.
+type AccessPolicy struct {
+	Allow map[string][]string                          // Allow maps a field to the only roles allowed to use it.
+	Deny  map[string][]string                          // Deny maps a field to the roles not allowed to use it.
+	Check func(ctx context.Context, field string) bool // Check optionally decides access after Allow and Deny.
+}
+
+// WithSqlAccessPolicy makes the schema authorize queries with policy, using the roles
+// of the context passed to ToSqlWhereContext, ToSqlPlanContext and ToSqlContext.
+func WithSqlAccessPolicy(policy AccessPolicy) SqlSchemaOption {
+	return func(schema *SqlSchema) {
+		schema.policy = &policy
+	}
+}
+
+// authorize returns an error if the caller in ctx may not use the query, see WithSqlAccessPolicy.
+// A search is checked against every search field, as it matches their values.
+func (s *SqlSchema) authorize(ctx context.Context, query Query) error {
+	if s.policy == nil {
+		return nil
+	}
+
+	if query.Search != "" && s.search != nil {
+		for _, field := range s.search.Fields {
+			if !s.policy.allowed(ctx, field) {
+				return &FieldError{Field: field, Err: ErrForbiddenField}
+			}
+		}
+	}
+
+	return s.policy.Authorize(ctx, query)
+}
+
+// Authorize returns an error if the query uses a field the caller may not use.
+// Conditions, sort, group, accumulator and preloads are all checked, and the fields
+// of preload sub-queries are checked prefixed with the preload name, e.g. comments.author.
+// Rules on a field also apply to its dotted subfields, such as JSON paths.
+func (p AccessPolicy) Authorize(ctx context.Context, query Query) error {
+	return p.authorize(ctx, "", query)
+}
+
+// authorize is Authorize with the fields of query prefixed with prefix.
+func (p AccessPolicy) authorize(ctx context.Context, prefix string, query Query) error {
+	for _, cond := range query.Conditions {
+		if !p.allowed(ctx, prefix+cond.Field) {
+			return &FieldError{Field: prefix + cond.Field, Op: cond.Op, Err: ErrForbiddenField}
+		}
+	}
+
+	fields := []string{}
+
+	for _, sort := range query.Sort {
+		fields = append(fields, sort.Field)
+	}
+
+	fields = append(fields, query.Group...)
+	fields = append(fields, query.Accumulator...)
+	fields = append(fields, query.With...)
+
+	for _, field := range fields {
+		if !p.allowed(ctx, prefix+field) {
+			return &FieldError{Field: prefix + field, Err: ErrForbiddenField}
+		}
+	}
+
+	for _, preload := range query.With {
+		if err := p.authorize(ctx, prefix+preload+".", query.WithQuery[preload]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// allowed returns true if the caller in ctx may use the field and every field it is
+// nested in, such as metadata for metadata.color or items for items.sku.
+func (p AccessPolicy) allowed(ctx context.Context, field string) bool {
+	for i := 0; i < len(field); i++ {
+		if field[i] == '.' && !p.allowedField(ctx, field[:i]) {
+			return false
+		}
+	}
+
+	return p.allowedField(ctx, field)
+}
+
+// allowedField returns true if the caller in ctx may use the field itself.
+func (p AccessPolicy) allowedField(ctx context.Context, field string) bool {
+	roles := RolesFromContext(ctx)
+
+	for _, role := range roles {
+		if sliceContainsString(p.Deny[field], role) {
+			return false
+		}
+	}
+
+	if allow, ok := p.Allow[field]; ok {
+		permitted := false
+
+		for _, role := range roles {
+			if sliceContainsString(allow, role) {
+				permitted = true
+				break
+			}
+		}
+
+		if !permitted {
+			return false
+		}
+	}
+
+	if p.Check != nil {
+		return p.Check(ctx, field)
+	}
+
+	return true
+}
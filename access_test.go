@@ -0,0 +1,251 @@
+package talkback
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessPolicyAuthorize(t *testing.T) {
+	policy := AccessPolicy{
+		Allow: map[string][]string{
+			"salary":         {"admin"},
+			"manager.salary": {"admin"},
+			"metadata":       {"admin"},
+			"items":          {"admin"},
+		},
+		Deny: map[string][]string{
+			"email": {"guest"},
+		},
+		Check: func(ctx context.Context, field string) bool {
+			return field != "secret"
+		},
+	}
+
+	type scenarioT struct {
+		name  string
+		roles []string
+		query Query
+		err   error
+	}
+
+	scenarios := []scenarioT{
+		{
+			name:  "admin filters salary",
+			roles: []string{"admin"},
+			query: Query{
+				Conditions: []Condition{
+					{"salary", "gt", []string{"100"}},
+				},
+			},
+			err: nil,
+		},
+		{
+			name:  "user filters salary",
+			roles: []string{"user"},
+			query: Query{
+				Conditions: []Condition{
+					{"salary", "gt", []string{"100"}},
+				},
+			},
+			err: &FieldError{Field: "salary", Op: "gt", Err: ErrForbiddenField},
+		},
+		{
+			name:  "user sorts salary",
+			roles: []string{"user"},
+			query: Query{
+				Sort: []Sort{{Field: "salary", Reverse: true}},
+			},
+			err: &FieldError{Field: "salary", Err: ErrForbiddenField},
+		},
+		{
+			name:  "guest groups email",
+			roles: []string{"user", "guest"},
+			query: Query{
+				Group: []string{"email"},
+			},
+			err: &FieldError{Field: "email", Err: ErrForbiddenField},
+		},
+		{
+			name:  "user accumulates email",
+			roles: []string{"user"},
+			query: Query{
+				Accumulator: []string{"email"},
+			},
+			err: nil,
+		},
+		{
+			name:  "admin preloads secret",
+			roles: []string{"admin"},
+			query: Query{
+				With: []string{"secret"},
+			},
+			err: &FieldError{Field: "secret", Err: ErrForbiddenField},
+		},
+		{
+			name:  "user filters metadata path",
+			roles: []string{"user"},
+			query: Query{
+				Conditions: []Condition{
+					{"metadata.color", "eq", []string{"red"}},
+				},
+			},
+			err: &FieldError{Field: "metadata.color", Op: "eq", Err: ErrForbiddenField},
+		},
+		{
+			name:  "admin filters metadata path",
+			roles: []string{"admin"},
+			query: Query{
+				Conditions: []Condition{
+					{"metadata.color", "eq", []string{"red"}},
+				},
+			},
+			err: nil,
+		},
+		{
+			name:  "user filters relation",
+			roles: []string{"user"},
+			query: Query{
+				Conditions: []Condition{
+					{"items.sku", "eq", []string{"X"}},
+				},
+			},
+			err: &FieldError{Field: "items.sku", Op: "eq", Err: ErrForbiddenField},
+		},
+		{
+			name:  "user filters preloaded salary",
+			roles: []string{"user"},
+			query: Query{
+				With: []string{"manager"},
+				WithQuery: map[string]Query{
+					"manager": {
+						Conditions: []Condition{
+							{"salary", "gt", []string{"100"}},
+						},
+					},
+				},
+			},
+			err: &FieldError{Field: "manager.salary", Op: "gt", Err: ErrForbiddenField},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			ctx := WithRoles(context.Background(), scenario.roles...)
+			err := policy.Authorize(ctx, scenario.query)
+
+			assert.Equal(t, scenario.err, err, "err should be equal")
+		})
+	}
+}
+
+func TestSqlSchemaAccessPolicy(t *testing.T) {
+	schema := MustCompileSqlSchema(SqlTranslations{
+		"name":   SqlFieldTranslation{},
+		"salary": SqlFieldTranslation{TypeConverter: SqlConvertInt},
+	}, nil, WithSqlRelations(SqlRelations{
+		"comments": SqlRelation{
+			Table:      "comments",
+			LocalKey:   "users.id",
+			ForeignKey: "user_id",
+			Translations: SqlTranslations{
+				"author": SqlFieldTranslation{},
+			},
+			Preload: "Comment",
+		},
+	}), WithSqlAccessPolicy(AccessPolicy{
+		Allow: map[string][]string{
+			"salary":          {"admin"},
+			"comments.author": {"admin"},
+		},
+	}))
+
+	type scenarioT struct {
+		name  string
+		roles []string
+		query Query
+		err   error
+	}
+
+	scenarios := []scenarioT{
+		{
+			name:  "admin filters salary",
+			roles: []string{"admin"},
+			query: Query{
+				Conditions: []Condition{
+					{"salary", "gt", []string{"100"}},
+				},
+			},
+			err: nil,
+		},
+		{
+			name:  "guest filters salary",
+			roles: []string{"guest"},
+			query: Query{
+				Conditions: []Condition{
+					{"salary", "gt", []string{"100"}},
+				},
+			},
+			err: &FieldError{Field: "salary", Op: "gt", Err: ErrForbiddenField},
+		},
+		{
+			name:  "guest filters relation author",
+			roles: []string{"guest"},
+			query: Query{
+				Conditions: []Condition{
+					{"comments.author", "eq", []string{"me"}},
+				},
+			},
+			err: &FieldError{Field: "comments.author", Op: "eq", Err: ErrForbiddenField},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			ctx := WithRoles(context.Background(), scenario.roles...)
+
+			_, _, err := schema.ToSqlWhereContext(ctx, scenario.query)
+			assert.Equal(t, scenario.err, err, "where err should be equal")
+
+			_, err = schema.ToSqlPlanContext(ctx, scenario.query)
+			assert.Equal(t, scenario.err, err, "plan err should be equal")
+
+			_, _, err = schema.ToSqlContext(ctx, "users", scenario.query)
+			assert.Equal(t, scenario.err, err, "sql err should be equal")
+		})
+	}
+
+	ctx := WithRoles(context.Background(), "guest")
+
+	_, err := schema.ToSqlPlanContext(ctx, Query{
+		With: []string{"comments"},
+		WithQuery: map[string]Query{
+			"comments": {Sort: []Sort{{Field: "author"}}},
+		},
+	})
+
+	assert.Equal(t, &FieldError{Field: "comments.author", Err: ErrForbiddenField}, err, "preload err should be equal")
+}
+
+func TestSqlSchemaAccessPolicySearch(t *testing.T) {
+	schema := MustCompileSqlSchema(SqlTranslations{
+		"name":   SqlFieldTranslation{},
+		"salary": SqlFieldTranslation{},
+	}, nil, WithSqlSearch(SqlSearch{Fields: []string{"name", "salary"}}), WithSqlAccessPolicy(AccessPolicy{
+		Allow: map[string][]string{
+			"salary": {"admin"},
+		},
+	}))
+
+	query := Query{Search: "100"}
+
+	_, _, err := schema.ToSqlWhereContext(WithRoles(context.Background(), "admin"), query)
+	assert.NoError(t, err, "admin error should be nil")
+
+	_, _, err = schema.ToSqlWhereContext(WithRoles(context.Background(), "guest"), query)
+	assert.Equal(t, &FieldError{Field: "salary", Err: ErrForbiddenField}, err, "guest err should be equal")
+
+	_, _, err = schema.ToSqlWhereContext(WithRoles(context.Background(), "guest"), Query{})
+	assert.NoError(t, err, "guest without search error should be nil")
+}
@@ -0,0 +1,173 @@
+package talkback
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SqlKind is the kind of value stored in a column.
+type SqlKind int
+
+const (
+	SqlKindScalar    SqlKind = iota // a single value
+	SqlKindArray                    // a native array, such as text[] in Postgres
+	SqlKindJSONArray                // a JSON array, such as jsonb in Postgres
+	SqlKindJSON                     // a JSON object filtered by dotted subpaths, such as metadata.color
+)
+
+// isArray returns true if the kind is an array kind.
+func (k SqlKind) isArray() bool {
+	return k == SqlKindArray || k == SqlKindJSONArray
+}
+
+// validateArray validates the kind of a translation for dialect, see validateSqlOps for array operations.
+func validateArray(dialect SqlDialect, field string, translation SqlFieldTranslation) error {
+	if translation.Kind == SqlKindArray && dialect != SqlPostgres {
+		return &FieldError{Field: field, Err: ErrInvalidKind}
+	}
+
+	return nil
+}
+
+// arrayLengthOps maps array length operations to their comparison operators.
+var arrayLengthOps = map[string]string{
+	OpLenEq:  "=",
+	OpLenNe:  "!=",
+	OpLenGt:  ">",
+	OpLenGte: ">=",
+	OpLenLt:  "<",
+	OpLenLte: "<=",
+}
+
+// arrayConditionToSql converts a Condition with an array operation to a SQL statement and arguments.
+func arrayConditionToSql(dialect SqlDialect, translation SqlFieldTranslation, cond Condition) (string, []interface{}, error) {
+	column := translation.Column
+
+	if operator, ok := arrayLengthOps[cond.Op]; ok {
+		length, err := strconv.Atoi(cond.Values[0])
+		if err != nil {
+			err := fmt.Errorf("%w: %q is not a length", ErrInvalidValue, cond.Values[0])
+			return "", nil, &FieldError{Field: cond.Field, Op: cond.Op, Err: err}
+		}
+
+		return arrayLength(dialect, translation.Kind, column) + " " + operator + " ?", []interface{}{length}, nil
+	}
+
+	values, err := sliceValuesToSql(translation, cond.Values)
+	if err != nil {
+		return "", nil, &FieldError{Field: cond.Field, Op: cond.Op, Err: err}
+	}
+
+	if translation.Kind == SqlKindArray {
+		switch cond.Op {
+		case OpHas:
+			return "? = ANY(" + column + ")", values, nil
+		case OpHasAll:
+			return column + " @> " + arrayPlaceholders(len(values)), values, nil
+		default:
+			return column + " && " + arrayPlaceholders(len(values)), values, nil
+		}
+	}
+
+	switch dialect {
+	case SqlMySQL:
+		doc, err := json.Marshal(values)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if cond.Op == OpHasAny {
+			return "JSON_OVERLAPS(" + column + ", ?)", []interface{}{string(doc)}, nil
+		}
+
+		return "JSON_CONTAINS(" + column + ", ?)", []interface{}{string(doc)}, nil
+	case SqlSQLite:
+		values = uniqueValues(values)
+		each := "FROM json_each(" + column + ") WHERE json_each.value IN (" + placeholders(len(values)) + ")"
+
+		if cond.Op == OpHasAll {
+			count := "(SELECT COUNT(DISTINCT json_each.value) " + each + ") = " + strconv.Itoa(len(values))
+			return count, values, nil
+		}
+
+		return "EXISTS (SELECT 1 " + each + ")", values, nil
+	default:
+		if cond.Op == OpHasAll {
+			doc, err := json.Marshal(values)
+			if err != nil {
+				return "", nil, err
+			}
+
+			return column + " @> CAST(? AS jsonb)", []interface{}{string(doc)}, nil
+		}
+
+		statements := []string{}
+		args := []interface{}{}
+
+		for _, value := range values {
+			doc, err := json.Marshal([]interface{}{value})
+			if err != nil {
+				return "", nil, err
+			}
+
+			statements = append(statements, column+" @> CAST(? AS jsonb)")
+			args = append(args, string(doc))
+		}
+
+		if len(statements) == 1 {
+			return statements[0], args, nil
+		}
+
+		return "(" + strings.Join(statements, " OR ") + ")", args, nil
+	}
+}
+
+// arrayLength returns the length expression of an array column.
+func arrayLength(dialect SqlDialect, kind SqlKind, column string) string {
+	if kind == SqlKindArray {
+		return "COALESCE(cardinality(" + column + "), 0)"
+	}
+
+	switch dialect {
+	case SqlMySQL:
+		return "JSON_LENGTH(" + column + ")"
+	case SqlSQLite:
+		return "json_array_length(" + column + ")"
+	default:
+		return "jsonb_array_length(" + column + ")"
+	}
+}
+
+// arrayPlaceholders returns a Postgres array constructor of n placeholders.
+func arrayPlaceholders(n int) string {
+	return "ARRAY[" + placeholders(n) + "]"
+}
+
+// placeholders returns n comma separated placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// uniqueValues returns values without duplicates, keeping the first occurrence.
+func uniqueValues(values []interface{}) []interface{} {
+	result := []interface{}{}
+
+	for _, value := range values {
+		duplicate := false
+
+		for _, r := range result {
+			if r == value {
+				duplicate = true
+				break
+			}
+		}
+
+		if !duplicate {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
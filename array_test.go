@@ -0,0 +1,103 @@
+package talkback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlArrayWhere(t *testing.T) {
+	arrayOpsOnly := []string{OpHas, OpHasAll, OpHasAny, OpLenGt}
+
+	query := Query{
+		Conditions: []Condition{
+			{"tags", "has", []string{"a"}},
+			{"tags", "hasall", []string{"a", "b"}},
+			{"tags", "hasany", []string{"a", "b"}},
+			{"tags", "lengt", []string{"2"}},
+		},
+	}
+
+	type scenarioT struct {
+		name      string
+		dialect   SqlDialect
+		kind      SqlKind
+		statement string
+		args      []interface{}
+	}
+
+	scenarios := []scenarioT{
+		{
+			name:      "postgres array",
+			dialect:   SqlPostgres,
+			kind:      SqlKindArray,
+			statement: `? = ANY("tags") AND "tags" @> ARRAY[?, ?] AND "tags" && ARRAY[?, ?] AND COALESCE(cardinality("tags"), 0) > ?`,
+			args:      []interface{}{"a", "a", "b", "a", "b", 2},
+		},
+		{
+			name:      "postgres json array",
+			dialect:   SqlPostgres,
+			kind:      SqlKindJSONArray,
+			statement: `"tags" @> CAST(? AS jsonb) AND "tags" @> CAST(? AS jsonb) AND ("tags" @> CAST(? AS jsonb) OR "tags" @> CAST(? AS jsonb)) AND jsonb_array_length("tags") > ?`,
+			args:      []interface{}{`["a"]`, `["a","b"]`, `["a"]`, `["b"]`, 2},
+		},
+		{
+			name:      "mysql json array",
+			dialect:   SqlMySQL,
+			kind:      SqlKindJSONArray,
+			statement: "JSON_CONTAINS(`tags`, ?) AND JSON_CONTAINS(`tags`, ?) AND JSON_OVERLAPS(`tags`, ?) AND JSON_LENGTH(`tags`) > ?",
+			args:      []interface{}{`["a"]`, `["a","b"]`, `["a","b"]`, 2},
+		},
+		{
+			name:      "sqlite json array",
+			dialect:   SqlSQLite,
+			kind:      SqlKindJSONArray,
+			statement: `EXISTS (SELECT 1 FROM json_each("tags") WHERE json_each.value IN (?)) AND (SELECT COUNT(DISTINCT json_each.value) FROM json_each("tags") WHERE json_each.value IN (?, ?)) = 2 AND EXISTS (SELECT 1 FROM json_each("tags") WHERE json_each.value IN (?, ?)) AND json_array_length("tags") > ?`,
+			args:      []interface{}{"a", "a", "b", "a", "b", 2},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			schema := MustCompileSqlSchema(SqlTranslations{
+				"tags": SqlFieldTranslation{
+					Kind: scenario.kind,
+					Ops:  arrayOpsOnly,
+				},
+			}, nil, WithSqlDialect(scenario.dialect))
+
+			statement, args, err := schema.ToSqlWhere(query)
+
+			assert.NoError(t, err, "error should be nil")
+			assert.Equal(t, scenario.statement, statement, "statement should be equal")
+			assert.Equal(t, scenario.args, args, "args should be equal")
+		})
+	}
+}
+
+func TestSqlArrayValidation(t *testing.T) {
+	_, err := CompileSqlSchema(SqlTranslations{
+		"tags": SqlFieldTranslation{
+			Ops: []string{OpHas},
+		},
+	}, nil)
+	assert.Equal(t, &FieldError{Field: "tags", Op: "has", Err: ErrInvalidKind}, err, "err should be equal")
+
+	_, err = CompileSqlSchema(SqlTranslations{
+		"tags": SqlFieldTranslation{
+			Kind: SqlKindArray,
+		},
+	}, nil, WithSqlDialect(SqlMySQL))
+	assert.Equal(t, &FieldError{Field: "tags", Err: ErrInvalidKind}, err, "err should be equal")
+
+	_, _, err = ToSqlWhere(Query{
+		Conditions: []Condition{
+			{"tags", "has", []string{"a"}},
+		},
+	}, SqlTranslations{
+		"tags": SqlFieldTranslation{
+			Kind: SqlKindArray,
+		},
+	})
+	assert.Equal(t, &FieldError{Field: "tags", Op: "has", Err: ErrDisallowedOp}, err, "err should be equal")
+}
@@ -0,0 +1,122 @@
+package talkback
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SqlConvertUUID is a TypeConverter that converts a UUID to its canonical lowercase
+// hyphenated string. Hyphenless, braced and urn:uuid: forms are accepted.
+func SqlConvertUUID(value string) (interface{}, error) {
+	s := strings.ToLower(value)
+	s = strings.TrimPrefix(s, "urn:uuid:")
+
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+
+	if len(s) == 36 && s[8] == '-' && s[13] == '-' && s[18] == '-' && s[23] == '-' {
+		s = strings.ReplaceAll(s, "-", "")
+	}
+
+	if len(s) != 32 {
+		return nil, fmt.Errorf("%w: %q is not a valid uuid", ErrInvalidValue, value)
+	}
+
+	if _, err := hex.DecodeString(s); err != nil {
+		return nil, fmt.Errorf("%w: %q is not a valid uuid", ErrInvalidValue, value)
+	}
+
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32], nil
+}
+
+// SqlConvertInt64 is a TypeConverter that converts a string to an int64.
+func SqlConvertInt64(value string) (interface{}, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not a valid integer", ErrInvalidValue, value)
+	}
+
+	return n, nil
+}
+
+// SqlConvertUint is a TypeConverter that converts a string to a uint64.
+func SqlConvertUint(value string) (interface{}, error) {
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not a valid unsigned integer", ErrInvalidValue, value)
+	}
+
+	return n, nil
+}
+
+// sqlDecimalPattern matches a decimal number without exponent.
+var sqlDecimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// SqlConvertDecimal is a TypeConverter that validates a decimal number and keeps it
+// as a string, so that no precision is lost to floating point (e.g. for money).
+func SqlConvertDecimal(value string) (interface{}, error) {
+	if !sqlDecimalPattern.MatchString(value) {
+		return nil, fmt.Errorf("%w: %q is not a valid decimal", ErrInvalidValue, value)
+	}
+
+	return strings.TrimPrefix(value, "+"), nil
+}
+
+// SqlConvertUnix is a TypeConverter that converts Unix epoch seconds to a time.Time in UTC.
+func SqlConvertUnix(value string) (interface{}, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not a valid unix time", ErrInvalidValue, value)
+	}
+
+	return time.Unix(n, 0).UTC(), nil
+}
+
+// SqlConvertUnixMilli is a TypeConverter that converts Unix epoch milliseconds to a time.Time in UTC.
+func SqlConvertUnixMilli(value string) (interface{}, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not a valid unix time", ErrInvalidValue, value)
+	}
+
+	return time.UnixMilli(n).UTC(), nil
+}
+
+// SqlConvertDuration is a TypeConverter that converts a duration such as 1h30m to a time.Duration.
+func SqlConvertDuration(value string) (interface{}, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not a valid duration", ErrInvalidValue, value)
+	}
+
+	return d, nil
+}
+
+// SqlConvertTimeLayouts returns a TypeConverter that converts a time in any of layouts to a time.Time.
+func SqlConvertTimeLayouts(layouts ...string) func(value string) (interface{}, error) {
+	return func(value string) (interface{}, error) {
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+
+		return nil, fmt.Errorf("%w: %q is not a valid time", ErrInvalidValue, value)
+	}
+}
+
+// SqlConvertEnum returns a TypeConverter that only accepts one of allowed.
+func SqlConvertEnum(allowed ...string) func(value string) (interface{}, error) {
+	return func(value string) (interface{}, error) {
+		if !sliceContainsString(allowed, value) {
+			return nil, fmt.Errorf("%w: %q is not one of %s", ErrInvalidValue, value, strings.Join(allowed, ", "))
+		}
+
+		return value, nil
+	}
+}
@@ -0,0 +1,64 @@
+package talkback
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlConverters(t *testing.T) {
+	type scenarioT struct {
+		name      string
+		converter func(value string) (interface{}, error)
+		value     string
+		out       interface{}
+	}
+
+	scenarios := []scenarioT{
+		{"uuid", SqlConvertUUID, "6BA7B810-9DAD-11D1-80B4-00C04FD430C8", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"uuid hyphenless", SqlConvertUUID, "6ba7b8109dad11d180b400c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"uuid braced", SqlConvertUUID, "{6ba7b810-9dad-11d1-80b4-00c04fd430c8}", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"uuid urn", SqlConvertUUID, "urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"int64", SqlConvertInt64, "9007199254740993", int64(9007199254740993)},
+		{"uint", SqlConvertUint, "18446744073709551615", uint64(18446744073709551615)},
+		{"decimal", SqlConvertDecimal, "+12345678901234567890.10", "12345678901234567890.10"},
+		{"decimal fraction", SqlConvertDecimal, "-.5", "-.5"},
+		{"unix", SqlConvertUnix, "1688169600", time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"unix milli", SqlConvertUnixMilli, "1688169600500", time.Date(2023, time.July, 1, 0, 0, 0, 500000000, time.UTC)},
+		{"duration", SqlConvertDuration, "1h30m", 90 * time.Minute},
+		{"time layouts", SqlConvertTimeLayouts("02/01/2006", "2006-01-02"), "01/07/2023", time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"enum", SqlConvertEnum("open", "closed"), "open", "open"},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			out, err := scenario.converter(scenario.value)
+
+			assert.NoError(t, err, "error should be nil")
+			assert.Equal(t, scenario.out, out, "out should be equal")
+		})
+	}
+
+	invalids := []scenarioT{
+		{name: "uuid", converter: SqlConvertUUID, value: "6ba7b810-9dad-11d1-80b4-00c04fd430cz"},
+		{name: "uuid length", converter: SqlConvertUUID, value: "6ba7b810"},
+		{name: "int64", converter: SqlConvertInt64, value: "9223372036854775808"},
+		{name: "uint", converter: SqlConvertUint, value: "-1"},
+		{name: "decimal", converter: SqlConvertDecimal, value: "1e10"},
+		{name: "unix", converter: SqlConvertUnix, value: "yesterday"},
+		{name: "unix milli", converter: SqlConvertUnixMilli, value: "1.5"},
+		{name: "duration", converter: SqlConvertDuration, value: "1 hour"},
+		{name: "time layouts", converter: SqlConvertTimeLayouts("2006-01-02"), value: "01/07/2023"},
+		{name: "enum", converter: SqlConvertEnum("open", "closed"), value: "pending"},
+	}
+
+	for _, scenario := range invalids {
+		t.Run("invalid "+scenario.name, func(t *testing.T) {
+			_, err := scenario.converter(scenario.value)
+
+			assert.ErrorIs(t, err, ErrInvalidValue, "err should be invalid value")
+			assert.ErrorContains(t, err, `"`+scenario.value+`"`, "err should name the value")
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package talkback
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// SqlDialect is the flavour of SQL generated by a SqlSchema.
+type SqlDialect string
+
+const (
+	SqlPostgres SqlDialect = "postgres" // PostgreSQL
+	SqlMySQL    SqlDialect = "mysql"    // MySQL 8 and MariaDB
+	SqlSQLite   SqlDialect = "sqlite"   // SQLite with a REGEXP function
+)
+
+// validSqlDialects is a list of supported dialects.
+var validSqlDialects = []SqlDialect{
+	SqlPostgres,
+	SqlMySQL,
+	SqlSQLite,
+}
+
+// valid returns true if the dialect is supported.
+func (d SqlDialect) valid() bool {
+	for _, dialect := range validSqlDialects {
+		if d == dialect {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSqlColumn returns true if name is a plain, optionally qualified, column name.
+func isSqlColumn(name string) bool {
+	start := 0
+
+	for i := 0; i <= len(name); i++ {
+		if i == len(name) || name[i] == '.' {
+			if !isSqlAlias(name[start:i]) {
+				return false
+			}
+
+			start = i + 1
+		}
+	}
+
+	return true
+}
+
+// isSqlAlias returns true if name is a plain identifier, such as an alias.
+func isSqlAlias(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		letter := c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sqlCollationPattern matches collation names.
+var sqlCollationPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// quote quotes an identifier, quoting each part of a qualified one separately.
+func (d SqlDialect) quote(identifier string) string {
+	if !strings.Contains(identifier, ".") {
+		return d.quotePart(identifier)
+	}
+
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		parts[i] = d.quotePart(part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// quotePart quotes a single identifier, which may contain dots.
+func (d SqlDialect) quotePart(identifier string) string {
+	quote := `"`
+	if d == SqlMySQL {
+		quote = "`"
+	}
+
+	return quote + strings.ReplaceAll(identifier, quote, quote+quote) + quote
+}
+
+// orderBy returns the ORDER BY statement of key sorted by column, emulating
+// the placement of nulls where NULLS FIRST and NULLS LAST are not supported.
+func (d SqlDialect) orderBy(column string, key string, reverse bool, nulls string) string {
+	direction := " ASC"
+	if reverse {
+		direction = " DESC"
+	}
+
+	switch {
+	case nulls == "":
+		return key + direction
+	case d == SqlMySQL && nulls == NullsFirst:
+		return column + " IS NULL DESC, " + key + direction
+	case d == SqlMySQL:
+		return column + " IS NULL ASC, " + key + direction
+	case nulls == NullsFirst:
+		return key + direction + " NULLS FIRST"
+	default:
+		return key + direction + " NULLS LAST"
+	}
+}
+
+// castAsText casts a column as text.
+func (d SqlDialect) castAsText(column string) string {
+	if d == SqlMySQL {
+		return "CAST(" + column + " AS CHAR)"
+	}
+
+	return "CAST(" + column + " AS TEXT)"
+}
+
+// like returns a LIKE statement on column, optionally case insensitive and negated.
+func (d SqlDialect) like(column string, insensitive bool, negate bool) string {
+	not := ""
+	if negate {
+		not = "NOT "
+	}
+
+	if !insensitive {
+		return d.castAsText(column) + " " + not + "LIKE ?"
+	}
+
+	if d == SqlPostgres {
+		return d.castAsText(column) + " " + not + "ILIKE ?"
+	}
+
+	return "LOWER(" + d.castAsText(column) + ") " + not + "LIKE LOWER(?)"
+}
+
+// regex returns a regular expression match statement on column and its argument.
+func (d SqlDialect) regex(column string, op string, pattern string) (string, interface{}) {
+	insensitive := op == OpIregex || op == OpNiregex
+	negate := op == OpNregex || op == OpNiregex
+
+	switch d {
+	case SqlMySQL:
+		matchType := "'c'"
+		if insensitive {
+			matchType = "'i'"
+		}
+
+		statement := "REGEXP_LIKE(" + column + ", ?, " + matchType + ")"
+		if negate {
+			statement = "NOT " + statement
+		}
+
+		return statement, pattern
+	case SqlSQLite:
+		if insensitive {
+			pattern = "(?i)" + pattern
+		}
+
+		if negate {
+			return column + " NOT REGEXP ?", pattern
+		}
+
+		return column + " REGEXP ?", pattern
+	default:
+		operator := "~"
+		if insensitive {
+			operator += "*"
+		}
+
+		if negate {
+			operator = "!" + operator
+		}
+
+		return column + " " + operator + " ?", pattern
+	}
+}
+
+// SqlRegexGuard returns a guard for WithSqlRegexGuard rejecting patterns longer
+// than maxLength, patterns that do not parse, and nested repetitions such as
+// (a+)+ which are prone to catastrophic backtracking.
+func SqlRegexGuard(maxLength int) func(pattern string) error {
+	return func(pattern string) error {
+		if len(pattern) > maxLength {
+			return fmt.Errorf("%w: pattern is longer than %d characters", ErrInvalidValue, maxLength)
+		}
+
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			return fmt.Errorf("%w: %q is not a valid pattern", ErrInvalidValue, pattern)
+		}
+
+		if hasNestedRepeat(re, false) {
+			return fmt.Errorf("%w: %q has nested repetitions", ErrInvalidValue, pattern)
+		}
+
+		return nil
+	}
+}
+
+// hasNestedRepeat returns true if re contains a repetition inside another repetition.
+func hasNestedRepeat(re *syntax.Regexp, inRepeat bool) bool {
+	repeat := false
+
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus, syntax.OpRepeat:
+		repeat = true
+	}
+
+	if repeat && inRepeat {
+		return true
+	}
+
+	for _, sub := range re.Sub {
+		if hasNestedRepeat(sub, inRepeat || repeat) {
+			return true
+		}
+	}
+
+	return false
+}
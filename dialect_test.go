@@ -0,0 +1,239 @@
+package talkback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlDialectWhere(t *testing.T) {
+	translations := SqlTranslations{
+		"field1": SqlFieldTranslation{
+			Ops: []string{OpRegex, OpNregex, OpIregex, OpNiregex},
+		},
+		"field2": SqlFieldTranslation{},
+	}
+
+	query := Query{
+		Conditions: []Condition{
+			{"field1", "regex", []string{"^a"}},
+			{"field1", "nregex", []string{"^b"}},
+			{"field1", "iregex", []string{"^c"}},
+			{"field1", "niregex", []string{"^d"}},
+			{"field2", "contain", []string{"value1"}},
+			{"field2", "ncontains", []string{"value2"}},
+		},
+	}
+
+	type scenarioT struct {
+		dialect   SqlDialect
+		statement string
+		args      []interface{}
+	}
+
+	scenarios := []scenarioT{
+		{
+			dialect:   SqlPostgres,
+			statement: `"field1" ~ ? AND "field1" !~ ? AND "field1" ~* ? AND "field1" !~* ? AND CAST("field2" AS TEXT) ILIKE ? AND CAST("field2" AS TEXT) NOT LIKE ?`,
+			args:      []interface{}{"^a", "^b", "^c", "^d", "%value1%", "%value2%"},
+		},
+		{
+			dialect:   SqlMySQL,
+			statement: "REGEXP_LIKE(`field1`, ?, 'c') AND NOT REGEXP_LIKE(`field1`, ?, 'c') AND REGEXP_LIKE(`field1`, ?, 'i') AND NOT REGEXP_LIKE(`field1`, ?, 'i') AND LOWER(CAST(`field2` AS CHAR)) LIKE LOWER(?) AND CAST(`field2` AS CHAR) NOT LIKE ?",
+			args:      []interface{}{"^a", "^b", "^c", "^d", "%value1%", "%value2%"},
+		},
+		{
+			dialect:   SqlSQLite,
+			statement: `"field1" REGEXP ? AND "field1" NOT REGEXP ? AND "field1" REGEXP ? AND "field1" NOT REGEXP ? AND LOWER(CAST("field2" AS TEXT)) LIKE LOWER(?) AND CAST("field2" AS TEXT) NOT LIKE ?`,
+			args:      []interface{}{"^a", "^b", "(?i)^c", "(?i)^d", "%value1%", "%value2%"},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(string(scenario.dialect), func(t *testing.T) {
+			schema := MustCompileSqlSchema(translations, nil, WithSqlDialect(scenario.dialect))
+			statement, args, err := schema.ToSqlWhere(query)
+
+			assert.NoError(t, err, "error should be nil")
+			assert.Equal(t, scenario.statement, statement, "statement should be equal")
+			assert.Equal(t, scenario.args, args, "args should be equal")
+		})
+	}
+}
+
+func TestSqlRegexOptIn(t *testing.T) {
+	query := Query{
+		Conditions: []Condition{
+			{"field1", "regex", []string{"^a"}},
+		},
+	}
+
+	_, _, err := ToSqlWhere(query, SqlTranslations{"field1": SqlFieldTranslation{}})
+
+	assert.Equal(t, &FieldError{Field: "field1", Op: "regex", Err: ErrDisallowedOp}, err, "err should be equal")
+}
+
+func TestSqlRegexGuard(t *testing.T) {
+	guard := SqlRegexGuard(16)
+
+	assert.NoError(t, guard("^ab+c[0-9]*$"), "error should be nil")
+	assert.ErrorIs(t, guard("^abcdefghijklmnopq$"), ErrInvalidValue, "long pattern should be rejected")
+	assert.ErrorIs(t, guard("(a"), ErrInvalidValue, "invalid pattern should be rejected")
+	assert.ErrorIs(t, guard("(a+)+$"), ErrInvalidValue, "nested repetition should be rejected")
+
+	schema := MustCompileSqlSchema(SqlTranslations{
+		"field1": SqlFieldTranslation{
+			Ops: []string{OpRegex},
+		},
+	}, nil, WithSqlRegexGuard(guard))
+
+	_, _, err := schema.ToSqlWhere(Query{
+		Conditions: []Condition{
+			{"field1", "regex", []string{"(a*)*"}},
+		},
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidValue, "err should be invalid value")
+	assert.ErrorContains(t, err, "field1_regex: ", "err should name the field")
+}
+
+func TestCompileSqlSchemaInvalidDialect(t *testing.T) {
+	_, err := CompileSqlSchema(nil, nil, WithSqlDialect("oracle"))
+
+	assert.Equal(t, ErrInvalidDialect, err, "err should be equal")
+}
+
+func TestSqlQuoteIdentifiers(t *testing.T) {
+	translations := SqlTranslations{
+		"order": SqlFieldTranslation{},
+		"user":  SqlFieldTranslation{Column: "u.user", Alias: "group"},
+		"count": SqlFieldTranslation{Column: "COUNT(*)", Raw: true},
+	}
+
+	query := Query{
+		Conditions: []Condition{
+			{"order", "eq", []string{"1"}},
+			{"user", "eq", []string{"me"}},
+		},
+		Group:       []string{"user"},
+		Accumulator: []string{"count"},
+		Sort:        []Sort{{Field: "order", Reverse: false}},
+	}
+
+	type scenarioT struct {
+		dialect   SqlDialect
+		statement string
+	}
+
+	scenarios := []scenarioT{
+		{
+			dialect:   SqlPostgres,
+			statement: `SELECT "u"."user" AS "group", COUNT(*) AS "count" FROM orders WHERE "order" = ? AND "u"."user" = ? GROUP BY "u"."user" ORDER BY "order" ASC LIMIT 0 OFFSET 0`,
+		},
+		{
+			dialect:   SqlMySQL,
+			statement: "SELECT `u`.`user` AS `group`, COUNT(*) AS `count` FROM orders WHERE `order` = ? AND `u`.`user` = ? GROUP BY `u`.`user` ORDER BY `order` ASC LIMIT 0 OFFSET 0",
+		},
+		{
+			dialect:   SqlSQLite,
+			statement: `SELECT "u"."user" AS "group", COUNT(*) AS "count" FROM orders WHERE "order" = ? AND "u"."user" = ? GROUP BY "u"."user" ORDER BY "order" ASC LIMIT 0 OFFSET 0`,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(string(scenario.dialect), func(t *testing.T) {
+			schema := MustCompileSqlSchema(translations, nil, WithSqlDialect(scenario.dialect))
+
+			statement, args, err := schema.ToSql("orders", query)
+
+			assert.NoError(t, err, "error should be nil")
+			assert.Equal(t, scenario.statement, statement, "statement should be equal")
+			assert.Equal(t, []interface{}{"1", "me"}, args, "args should be equal")
+		})
+	}
+}
+
+func TestSqlInvalidIdentifiers(t *testing.T) {
+	type scenarioT struct {
+		name        string
+		translation SqlFieldTranslation
+		err         error
+	}
+
+	scenarios := []scenarioT{
+		{"expression column", SqlFieldTranslation{Column: "COUNT(*)"}, ErrInvalidColumn},
+		{"injected column", SqlFieldTranslation{Column: "id; DROP TABLE users"}, ErrInvalidColumn},
+		{"quoted column", SqlFieldTranslation{Column: `"id"`}, ErrInvalidColumn},
+		{"empty part", SqlFieldTranslation{Column: "ex..id"}, ErrInvalidColumn},
+		{"trailing dot", SqlFieldTranslation{Column: "ex."}, ErrInvalidColumn},
+		{"leading digit", SqlFieldTranslation{Column: "ex.1id"}, ErrInvalidColumn},
+		{"non ascii column", SqlFieldTranslation{Column: "prénom"}, ErrInvalidColumn},
+		{"leading digit alias", SqlFieldTranslation{Alias: "1id"}, ErrInvalidAlias},
+		{"qualified alias", SqlFieldTranslation{Alias: "ex.id"}, ErrInvalidAlias},
+		{"raw alias", SqlFieldTranslation{Column: "COUNT(*)", Raw: true, Alias: "a b"}, ErrInvalidAlias},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, err := CompileSqlSchema(SqlTranslations{"field": scenario.translation}, nil)
+			assert.ErrorIs(t, err, scenario.err, "err should be equal")
+		})
+	}
+}
+
+func TestSqlSortNulls(t *testing.T) {
+	translations := SqlTranslations{
+		"due_at": SqlFieldTranslation{},
+		"name":   SqlFieldTranslation{Nulls: NullsLast, Collation: "en_US.utf8"},
+	}
+
+	query := Query{
+		Sort: []Sort{
+			{Field: "due_at", Reverse: true, Nulls: NullsFirst},
+			{Field: "name"},
+			{Field: "due_at"},
+		},
+	}
+
+	type scenarioT struct {
+		dialect   SqlDialect
+		statement string
+	}
+
+	scenarios := []scenarioT{
+		{
+			dialect:   SqlPostgres,
+			statement: `"due_at" DESC NULLS FIRST, "name" COLLATE "en_US.utf8" ASC NULLS LAST, "due_at" ASC`,
+		},
+		{
+			dialect:   SqlMySQL,
+			statement: "`due_at` IS NULL DESC, `due_at` DESC, `name` IS NULL ASC, `name` COLLATE `en_US.utf8` ASC, `due_at` ASC",
+		},
+		{
+			dialect:   SqlSQLite,
+			statement: `"due_at" DESC NULLS FIRST, "name" COLLATE "en_US.utf8" ASC NULLS LAST, "due_at" ASC`,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(string(scenario.dialect), func(t *testing.T) {
+			schema := MustCompileSqlSchema(translations, nil, WithSqlDialect(scenario.dialect))
+
+			statement, err := schema.ToSqlOrderBy(query)
+
+			assert.NoError(t, err, "error should be nil")
+			assert.Equal(t, scenario.statement, statement, "statement should be equal")
+		})
+	}
+
+	t.Run("invalid nulls", func(t *testing.T) {
+		_, err := ToSqlOrderBy(Query{Sort: []Sort{{Field: "due_at", Nulls: "nullsmiddle"}}}, translations)
+		assert.ErrorIs(t, err, ErrInvalidSort, "err should be invalid sort")
+
+		_, err = CompileSqlSchema(SqlTranslations{"name": SqlFieldTranslation{Nulls: "last"}}, nil)
+		assert.ErrorIs(t, err, ErrInvalidSort, "err should be invalid sort")
+
+		_, err = CompileSqlSchema(SqlTranslations{"name": SqlFieldTranslation{Collation: `C" DESC`}}, nil)
+		assert.ErrorIs(t, err, ErrInvalidSort, "err should be invalid sort")
+	})
+}
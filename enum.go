@@ -0,0 +1,98 @@
+package talkback
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SqlEnumValue maps a public label of an enum field to the value stored in the column.
+type SqlEnumValue struct {
+	Label string      // Label is the value used by clients.
+	Value interface{} // Value is the value stored in the column, a string, bool or number.
+}
+
+// enumConverter returns a TypeConverter mapping the labels of enum to their stored values.
+func enumConverter(enum []SqlEnumValue) func(value string) (interface{}, error) {
+	labels := []string{}
+
+	for _, e := range enum {
+		labels = append(labels, e.Label)
+	}
+
+	return func(value string) (interface{}, error) {
+		for _, e := range enum {
+			if e.Label == value {
+				return e.Value, nil
+			}
+		}
+
+		return nil, fmt.Errorf("%w: %q is not one of %s", ErrInvalidValue, value, strings.Join(labels, ", "))
+	}
+}
+
+// validateEnum validates the labels and values of an enum field.
+func validateEnum(dialect SqlDialect, field string, enum []SqlEnumValue) error {
+	labels := []string{}
+
+	for _, e := range enum {
+		if sliceContainsString(labels, e.Label) {
+			return &FieldError{Field: field, Err: fmt.Errorf("%w: duplicate label %q", ErrInvalidEnum, e.Label)}
+		}
+
+		if _, ok := sqlLiteral(dialect, e.Value); !ok {
+			return &FieldError{Field: field, Err: fmt.Errorf("%w: unsupported value %v", ErrInvalidEnum, e.Value)}
+		}
+
+		labels = append(labels, e.Label)
+	}
+
+	return nil
+}
+
+// enumOrder returns a CASE expression ordering column by the position of its value in enum.
+// Values not in enum are ordered last.
+func enumOrder(dialect SqlDialect, column string, enum []SqlEnumValue) string {
+	var b strings.Builder
+
+	b.WriteString("CASE " + column)
+
+	for i, e := range enum {
+		literal, _ := sqlLiteral(dialect, e.Value)
+		b.WriteString(" WHEN " + literal + " THEN " + strconv.Itoa(i))
+	}
+
+	b.WriteString(" ELSE " + strconv.Itoa(len(enum)) + " END")
+
+	return b.String()
+}
+
+// sqlLiteral returns value as a SQL literal, for values defined by the schema only.
+func sqlLiteral(dialect SqlDialect, value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		if dialect == SqlMySQL {
+			v = strings.ReplaceAll(v, `\`, `\\`)
+		}
+
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", true
+	case bool:
+		if dialect == SqlSQLite {
+			if v {
+				return "1", true
+			}
+
+			return "0", true
+		}
+
+		return strings.ToUpper(strconv.FormatBool(v)), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,90 @@
+package talkback
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlEnum(t *testing.T) {
+	translations := SqlTranslations{
+		"status": SqlFieldTranslation{
+			Enum: []SqlEnumValue{
+				{Label: "active", Value: 1},
+				{Label: "pending", Value: 0},
+				{Label: "closed", Value: 2},
+			},
+		},
+		"kind": SqlFieldTranslation{
+			Enum: []SqlEnumValue{
+				{Label: "a", Value: "it's"},
+				{Label: "b", Value: true},
+			},
+		},
+	}
+
+	t.Run("filter", func(t *testing.T) {
+		statement, args, err := ToSqlWhere(Query{
+			Conditions: []Condition{
+				{"status", "in", []string{"active", "closed"}},
+			},
+		}, translations)
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `"status" IN (?)`, statement, "statement should be equal")
+		assert.Equal(t, []interface{}{[]interface{}{1, 2}}, args, "args should be equal")
+	})
+
+	t.Run("unknown label", func(t *testing.T) {
+		_, _, err := ToSqlWhere(Query{
+			Conditions: []Condition{
+				{"status", "eq", []string{"archived"}},
+			},
+		}, translations)
+
+		assert.Equal(t, &FieldError{Field: "status", Op: "eq", Err: fmt.Errorf("%w: %q is not one of active, pending, closed", ErrInvalidValue, "archived")}, err, "err should be equal")
+	})
+
+	t.Run("text ops", func(t *testing.T) {
+		_, _, err := ToSqlWhere(Query{
+			Conditions: []Condition{
+				{"status", "contain", []string{"act"}},
+			},
+		}, translations)
+
+		assert.Equal(t, &FieldError{Field: "status", Op: "contain", Err: ErrDisallowedOp}, err, "err should be equal")
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		statement, err := ToSqlOrderBy(Query{
+			Sort: []Sort{{Field: "status", Reverse: true}, {Field: "kind", Reverse: false}},
+		}, translations)
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `CASE "status" WHEN 1 THEN 0 WHEN 0 THEN 1 WHEN 2 THEN 2 ELSE 3 END DESC, CASE "kind" WHEN 'it''s' THEN 0 WHEN TRUE THEN 1 ELSE 2 END ASC`, statement, "statement should be equal")
+	})
+
+	t.Run("invalid enum", func(t *testing.T) {
+		_, err := CompileSqlSchema(SqlTranslations{
+			"status": SqlFieldTranslation{
+				Enum: []SqlEnumValue{
+					{Label: "active", Value: 1},
+					{Label: "active", Value: 2},
+				},
+			},
+		}, nil)
+
+		assert.ErrorIs(t, err, ErrInvalidEnum, "err should be invalid enum")
+
+		_, err = CompileSqlSchema(SqlTranslations{
+			"status": SqlFieldTranslation{
+				Enum: []SqlEnumValue{
+					{Label: "active", Value: []int{1}},
+				},
+			},
+		}, nil)
+
+		assert.ErrorIs(t, err, ErrInvalidEnum, "err should be invalid enum")
+	})
+}
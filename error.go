@@ -3,7 +3,48 @@ package talkback
 import "errors"
 
 var (
-	ErrInvalidField   = errors.New("invalid field")
-	ErrInvalidOp      = errors.New("invalid op")
-	ErrInvalidPreload = errors.New("invalid preload")
+	ErrInvalidField       = errors.New("invalid field")
+	ErrInvalidOp          = errors.New("invalid op")
+	ErrInvalidPreload     = errors.New("invalid preload")
+	ErrDisallowedOp       = errors.New("disallowed op")
+	ErrNotFilterable      = errors.New("field is not filterable")
+	ErrNotSortable        = errors.New("field is not sortable")
+	ErrNotGroupable       = errors.New("field is not groupable")
+	ErrNotSelectable      = errors.New("field is not selectable")
+	ErrForbiddenField     = errors.New("forbidden field")
+	ErrInvalidScope       = errors.New("invalid scope")
+	ErrInvalidValue       = errors.New("invalid value")
+	ErrInvalidDialect     = errors.New("invalid dialect")
+	ErrInvalidSearch      = errors.New("invalid search")
+	ErrInvalidKind        = errors.New("invalid kind")
+	ErrInvalidEnum        = errors.New("invalid enum")
+	ErrInvalidExpr        = errors.New("invalid expression")
+	ErrExprArgs           = errors.New("expression has arguments, use a plan")
+	ErrAggregateCondition = errors.New("condition on aggregate field, use a plan")
+	ErrInvalidColumn      = errors.New("invalid column")
+	ErrInvalidAlias       = errors.New("invalid alias")
+	ErrInvalidSort        = errors.New("invalid sort")
+	ErrInvalidRelation    = errors.New("invalid relation")
+	ErrInvalidJoin        = errors.New("invalid join")
 )
+
+// FieldError is an error caused by a specific field (and op) of a query.
+type FieldError struct {
+	Field string // Field is the name of the offending field.
+	Op    string // Op is the offending operation, if any.
+	Err   error  // Err is the underlying error.
+}
+
+// Error returns the error message prefixed with the field and op.
+func (e *FieldError) Error() string {
+	if e.Op == "" {
+		return e.Field + ": " + e.Err.Error()
+	}
+
+	return e.Field + "_" + e.Op + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
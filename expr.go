@@ -0,0 +1,119 @@
+package talkback
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlExprMarker delimits the token standing for an expression field while a statement is built.
+const sqlExprMarker = "\x00"
+
+// sqlExprToken returns the token standing for the expression of field.
+func sqlExprToken(field string) string {
+	return sqlExprMarker + field + sqlExprMarker
+}
+
+// validateSqlExpr validates the expression of a translation before it is sanitized.
+func validateSqlExpr(field string, translation SqlFieldTranslation) error {
+	if translation.Expr == "" {
+		if len(translation.ExprArgs) > 0 || translation.Aggregate {
+			return &FieldError{Field: field, Err: fmt.Errorf("%w: missing expression", ErrInvalidExpr)}
+		}
+
+		return nil
+	}
+
+	if translation.Aggregate && translation.Scope != nil {
+		return &FieldError{Field: field, Err: fmt.Errorf("%w: aggregate fields cannot be scopes", ErrInvalidExpr)}
+	}
+
+	if translation.Column != "" {
+		return &FieldError{Field: field, Err: fmt.Errorf("%w: both column and expression are set", ErrInvalidExpr)}
+	}
+
+	if n := countPlaceholders(translation.Expr); n != len(translation.ExprArgs) {
+		err := fmt.Errorf("%w: %d placeholders but %d arguments", ErrInvalidExpr, n, len(translation.ExprArgs))
+		return &FieldError{Field: field, Err: err}
+	}
+
+	return nil
+}
+
+// countPlaceholders returns the number of placeholders in statement, outside of string literals.
+func countPlaceholders(statement string) int {
+	n := 0
+	quoted := false
+
+	for i := 0; i < len(statement); i++ {
+		switch {
+		case statement[i] == '\'':
+			quoted = !quoted
+		case statement[i] == '?' && !quoted:
+			n++
+		}
+	}
+
+	return n
+}
+
+// expandExprs replaces the expression tokens of statement by their parenthesised
+// expressions, merging the expression arguments with args in placeholder order.
+func (s *SqlSchema) expandExprs(statement string, args []interface{}) (string, []interface{}) {
+	if !strings.Contains(statement, sqlExprMarker) {
+		return statement, args
+	}
+
+	var b strings.Builder
+
+	var result []interface{}
+	quoted := false
+
+	for i := 0; i < len(statement); i++ {
+		c := statement[i]
+
+		switch {
+		case c == '\'':
+			quoted = !quoted
+			b.WriteByte(c)
+		case c == '?' && !quoted && len(args) > 0:
+			result = append(result, args[0])
+			args = args[1:]
+			b.WriteByte(c)
+		case c == sqlExprMarker[0] && !quoted:
+			end := strings.Index(statement[i+1:], sqlExprMarker)
+			translation := s.exprs[statement[i+1:i+1+end]]
+
+			b.WriteString("(" + translation.Expr + ")")
+			result = append(result, translation.ExprArgs...)
+			i += end + 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), append(result, args...)
+}
+
+// withoutExprArgs drops the arguments of statements, failing if there are any.
+func withoutExprArgs(statements []string, args []interface{}, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) > 0 {
+		return nil, ErrExprArgs
+	}
+
+	return statements, nil
+}
+
+// isAggregateField returns true if conditions on field go to HAVING, including JSON paths of aggregate fields.
+func (s *SqlSchema) isAggregateField(field string) bool {
+	if dot := strings.Index(field, "."); dot >= 0 {
+		if _, ok := s.translations[field]; !ok {
+			field = field[:dot]
+		}
+	}
+
+	return s.translations[field].Aggregate
+}
@@ -0,0 +1,157 @@
+package talkback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlExpr(t *testing.T) {
+	schema := MustCompileSqlSchema(SqlTranslations{
+		"name": SqlFieldTranslation{
+			Expr: "COALESCE(a.name, b.name)",
+		},
+		"score": SqlFieldTranslation{
+			Expr:     "a.points * ?",
+			ExprArgs: []interface{}{2},
+			Alias:    "weighted",
+		},
+		"total": SqlFieldTranslation{
+			Expr:          "SUM(a.amount)",
+			TypeConverter: SqlConvertInt,
+			Aggregate:     true,
+			UseAlias:      true,
+		},
+		"count": SqlFieldTranslation{
+			Expr:          "COUNT(CASE WHEN a.state = '?' THEN ? END)",
+			ExprArgs:      []interface{}{"open"},
+			TypeConverter: SqlConvertInt,
+			Aggregate:     true,
+		},
+	}, nil)
+
+	t.Run("where", func(t *testing.T) {
+		statement, args, err := schema.ToSqlWhere(Query{
+			Conditions: []Condition{
+				{"name", "contain", []string{"jo"}},
+				{"score", "gt", []string{"10"}},
+			},
+		})
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, "CAST((COALESCE(a.name, b.name)) AS TEXT) ILIKE ? AND (a.points * ?) > ?", statement, "statement should be equal")
+		assert.Equal(t, []interface{}{"%jo%", 2, "10"}, args, "args should be equal")
+	})
+
+	t.Run("where on aggregate", func(t *testing.T) {
+		_, _, err := schema.ToSqlWhere(Query{
+			Conditions: []Condition{
+				{"total", "gt", []string{"5"}},
+			},
+		})
+
+		assert.Equal(t, &FieldError{Field: "total", Op: "gt", Err: ErrAggregateCondition}, err, "err should be equal")
+	})
+
+	t.Run("having", func(t *testing.T) {
+		statement, args, err := schema.ToSqlHaving(Query{
+			Conditions: []Condition{
+				{"name", "eq", []string{"jo"}},
+				{"count", "gte", []string{"3"}},
+				{"total", "gt", []string{"5"}},
+			},
+		})
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `(COUNT(CASE WHEN a.state = '?' THEN ? END)) >= ? AND "total" > ?`, statement, "statement should be equal")
+		assert.Equal(t, []interface{}{"open", 3, 5}, args, "args should be equal")
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		plan, err := schema.ToSqlPlan(Query{
+			Conditions: []Condition{
+				{"score", "gt", []string{"10"}},
+				{"total", "gt", []string{"5"}},
+			},
+			Group:       []string{"name", "score"},
+			Accumulator: []string{"total"},
+			Sort:        []Sort{{Field: "total", Reverse: true}, {Field: "score", Reverse: false}},
+			Limit:       10,
+		})
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, SqlPlan{
+			Select:     `(COALESCE(a.name, b.name)) AS "name", (a.points * ?) AS "weighted", (SUM(a.amount)) AS "total"`,
+			SelectArgs: []interface{}{2},
+			Where:      "(a.points * ?) > ?",
+			WhereArgs:  []interface{}{2, "10"},
+			Group:      "(COALESCE(a.name, b.name)), (a.points * ?)",
+			GroupArgs:  []interface{}{2},
+			Having:     `"total" > ?`,
+			HavingArgs: []interface{}{5},
+			Order:      `"total" DESC, (a.points * ?) ASC`,
+			OrderArgs:  []interface{}{2},
+			Limit:      10,
+			Preload:    []string{},
+			Preloads:   []SqlPreloadPlan{},
+		}, plan, "plan should be equal")
+	})
+
+	t.Run("select with several args", func(t *testing.T) {
+		plan, err := schema.ToSqlPlan(Query{
+			Group:       []string{"score"},
+			Accumulator: []string{"count"},
+		})
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `(a.points * ?) AS "weighted", (COUNT(CASE WHEN a.state = '?' THEN ? END)) AS "count"`, plan.Select, "select should be equal")
+		assert.Equal(t, []interface{}{2, "open"}, plan.SelectArgs, "select args should be equal")
+	})
+
+	t.Run("to sql", func(t *testing.T) {
+		sql, args, err := schema.ToSql("a", Query{
+			Conditions: []Condition{
+				{"total", "gt", []string{"5"}},
+			},
+			Group:       []string{"name"},
+			Accumulator: []string{"total"},
+			Sort:        []Sort{{Field: "name", Reverse: false}},
+		})
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `SELECT (COALESCE(a.name, b.name)) AS "name", (SUM(a.amount)) AS "total" FROM a WHERE  GROUP BY (COALESCE(a.name, b.name)) HAVING "total" > ? ORDER BY (COALESCE(a.name, b.name)) ASC LIMIT 0 OFFSET 0`, sql, "sql should be equal")
+		assert.Equal(t, []interface{}{5}, args, "args should be equal")
+	})
+
+	t.Run("slice with args", func(t *testing.T) {
+		_, err := schema.ToSqlSelectSlice(Query{Group: []string{"score"}})
+		assert.Equal(t, ErrExprArgs, err, "err should be equal")
+
+		fields, err := schema.ToSqlGroupSlice(Query{Group: []string{"name"}})
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, []string{"(COALESCE(a.name, b.name))"}, fields, "fields should be equal")
+	})
+}
+
+func TestSqlExprInvalid(t *testing.T) {
+	type scenarioT struct {
+		name        string
+		translation SqlFieldTranslation
+	}
+
+	scenarios := []scenarioT{
+		{"column and expr", SqlFieldTranslation{Column: "a", Expr: "b"}},
+		{"missing args", SqlFieldTranslation{Expr: "a + ?"}},
+		{"extra args", SqlFieldTranslation{Expr: "a", ExprArgs: []interface{}{1}}},
+		{"args without expr", SqlFieldTranslation{ExprArgs: []interface{}{1}}},
+		{"aggregate without expr", SqlFieldTranslation{Aggregate: true}},
+		{"aggregate scope", SqlFieldTranslation{Expr: "COUNT(*)", Aggregate: true, Scope: &SqlScope{Values: []string{"1"}}}},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, err := CompileSqlSchema(SqlTranslations{"field": scenario.translation}, nil)
+			assert.ErrorIs(t, err, ErrInvalidExpr, "err should be invalid expression")
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package talkback
+
+import (
+	"strings"
+)
+
+// SqlJoin is a join added to a query only when a field referencing it is used.
+type SqlJoin struct {
+	Kind     string   // Kind is INNER, LEFT, RIGHT or FULL, defaults to INNER.
+	Table    string   // Table is the joined table, optionally followed by an alias, e.g. "extras ex".
+	On       string   // On is the raw SQL join condition, e.g. "ex.id = somewhere.extra_id".
+	Requires []string // Requires is a list of joins this join depends on.
+}
+
+// SqlJoins is a map of join names to joins.
+type SqlJoins map[string]SqlJoin
+
+// validSqlJoinKinds is a list of valid join kinds.
+var validSqlJoinKinds = []string{
+	"INNER",
+	"LEFT",
+	"RIGHT",
+	"FULL",
+}
+
+// WithSqlJoins sets the joins referenced by the Joins of translations.
+func WithSqlJoins(joins SqlJoins) SqlSchemaOption {
+	return func(schema *SqlSchema) {
+		schema.joinConfigs = joins
+	}
+}
+
+// compileJoins validates and compiles the joins of the schema and the joins referenced by its fields.
+func (s *SqlSchema) compileJoins() error {
+	if len(s.joinConfigs) > 0 {
+		s.joins = make(map[string]string, len(s.joinConfigs))
+	}
+
+	for name, join := range s.joinConfigs {
+		kind := strings.ToUpper(join.Kind)
+		if kind == "" {
+			kind = "INNER"
+		}
+
+		table := strings.Fields(join.Table)
+
+		valid := sliceContainsString(validSqlJoinKinds, kind) && join.On != "" &&
+			(len(table) == 1 || (len(table) == 2 && isSqlAlias(table[1]))) &&
+			isSqlColumn(table[0])
+
+		if !valid {
+			return &FieldError{Field: name, Err: ErrInvalidJoin}
+		}
+
+		statement := kind + " JOIN " + s.dialect.quote(table[0])
+		if len(table) == 2 {
+			statement += " AS " + s.dialect.quote(table[1])
+		}
+
+		s.joins[name] = statement + " ON " + join.On
+	}
+
+	for name := range s.joinConfigs {
+		if err := s.validateJoinRequires(name, []string{}); err != nil {
+			return err
+		}
+	}
+
+	for _, translations := range []SqlTranslations{s.translations, s.scoped} {
+		for field, translation := range translations {
+			for _, join := range translation.Joins {
+				if _, ok := s.joins[join]; !ok {
+					return &FieldError{Field: field, Err: ErrInvalidJoin}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateJoinRequires validates that the joins required by a join exist and do not require it back.
+func (s *SqlSchema) validateJoinRequires(name string, path []string) error {
+	if sliceContainsString(path, name) {
+		return &FieldError{Field: name, Err: ErrInvalidJoin}
+	}
+
+	for _, required := range s.joinConfigs[name].Requires {
+		if _, ok := s.joins[required]; !ok {
+			return &FieldError{Field: name, Err: ErrInvalidJoin}
+		}
+
+		if err := s.validateJoinRequires(required, append(path, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ToSqlJoin converts a Query to the SQL JOIN statements needed by its fields.
+func (s *SqlSchema) ToSqlJoin(query Query) string {
+	return strings.Join(s.ToSqlJoinSlice(query), " ")
+}
+
+// ToSqlJoinSlice converts a Query to a slice of the SQL JOIN statements needed by its fields,
+// in order of first use with the joins they require first. Unknown fields are ignored.
+func (s *SqlSchema) ToSqlJoinSlice(query Query) []string {
+	statements := []string{}
+
+	if len(s.joins) == 0 {
+		return statements
+	}
+
+	visited := map[string]bool{}
+
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+
+		visited[name] = true
+
+		for _, required := range s.joinConfigs[name].Requires {
+			visit(required)
+		}
+
+		statements = append(statements, s.joins[name])
+	}
+
+	for _, field := range s.joinedFields(query) {
+		translation, ok := s.translations[field]
+		if !ok {
+			translation = s.scoped[field]
+		}
+
+		for _, join := range translation.Joins {
+			visit(join)
+		}
+	}
+
+	return statements
+}
+
+// joinedFields returns the fields used by a Query and the scopes, in order of first use.
+func (s *SqlSchema) joinedFields(query Query) []string {
+	fields := append([]string{}, s.scopes...)
+
+	for _, cond := range query.Conditions {
+		field := cond.Field
+
+		if _, ok := s.translations[field]; !ok && strings.Contains(field, ".") {
+			field = field[:strings.Index(field, ".")]
+		}
+
+		fields = append(fields, field)
+	}
+
+	if query.Search != "" && s.search != nil {
+		fields = append(fields, s.search.Fields...)
+	}
+
+	fields = append(fields, query.Group...)
+	fields = append(fields, query.Accumulator...)
+
+	for _, sort := range s.sortsOf(query) {
+		fields = append(fields, sort.Field)
+	}
+
+	return fields
+}
@@ -0,0 +1,131 @@
+package talkback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlJoins(t *testing.T) {
+	schema := MustCompileSqlSchema(SqlTranslations{
+		"id":       SqlFieldTranslation{Column: "orders.id"},
+		"customer": SqlFieldTranslation{Column: "c.name", Joins: []string{"customer"}},
+		"country":  SqlFieldTranslation{Column: "co.name", Joins: []string{"country"}},
+		"item":     SqlFieldTranslation{Column: "i.sku", Joins: []string{"items"}},
+	}, nil, WithSqlJoins(SqlJoins{
+		"customer": SqlJoin{Table: "customers c", On: "c.id = orders.customer_id"},
+		"country":  SqlJoin{Kind: "left", Table: "countries co", On: "co.id = c.country_id", Requires: []string{"customer"}},
+		"items":    SqlJoin{Kind: "LEFT", Table: "order_items i", On: "i.order_id = orders.id"},
+	}))
+
+	type scenarioT struct {
+		name  string
+		query Query
+		joins []string
+	}
+
+	scenarios := []scenarioT{
+		{
+			name:  "no joins",
+			query: Query{Conditions: []Condition{{"id", "eq", []string{"1"}}}},
+			joins: []string{},
+		},
+		{
+			name: "dependencies first",
+			query: Query{
+				Conditions: []Condition{{"item", "eq", []string{"X"}}},
+				Sort:       []Sort{{Field: "country"}},
+			},
+			joins: []string{
+				`LEFT JOIN "order_items" AS "i" ON i.order_id = orders.id`,
+				`INNER JOIN "customers" AS "c" ON c.id = orders.customer_id`,
+				`LEFT JOIN "countries" AS "co" ON co.id = c.country_id`,
+			},
+		},
+		{
+			name: "used once",
+			query: Query{
+				Conditions:  []Condition{{"customer", "eq", []string{"me"}}},
+				Group:       []string{"customer", "country"},
+				Accumulator: []string{"customer"},
+			},
+			joins: []string{
+				`INNER JOIN "customers" AS "c" ON c.id = orders.customer_id`,
+				`LEFT JOIN "countries" AS "co" ON co.id = c.country_id`,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			assert.Equal(t, scenario.joins, schema.ToSqlJoinSlice(scenario.query), "joins should be equal")
+		})
+	}
+
+	t.Run("to sql", func(t *testing.T) {
+		sql, args, err := schema.ToSql("orders", Query{
+			Conditions:  []Condition{{"customer", "eq", []string{"me"}}},
+			Accumulator: []string{"id"},
+		})
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `SELECT "orders"."id" AS "id" FROM orders INNER JOIN "customers" AS "c" ON c.id = orders.customer_id WHERE "c"."name" = ? GROUP BY  ORDER BY  LIMIT 0 OFFSET 0`, sql, "sql should be equal")
+		assert.Equal(t, []interface{}{"me"}, args, "args should be equal")
+	})
+}
+
+func TestSqlJoinsInvalid(t *testing.T) {
+	type scenarioT struct {
+		name         string
+		translations SqlTranslations
+		joins        SqlJoins
+		field        string
+	}
+
+	scenarios := []scenarioT{
+		{
+			name:  "invalid kind",
+			joins: SqlJoins{"c": SqlJoin{Kind: "OUTER APPLY", Table: "c", On: "true"}},
+			field: "c",
+		},
+		{
+			name:  "invalid table",
+			joins: SqlJoins{"c": SqlJoin{Table: "c AS x", On: "true"}},
+			field: "c",
+		},
+		{
+			name:  "missing on",
+			joins: SqlJoins{"c": SqlJoin{Table: "c"}},
+			field: "c",
+		},
+		{
+			name:  "unknown requirement",
+			joins: SqlJoins{"c": SqlJoin{Table: "c", On: "true", Requires: []string{"d"}}},
+			field: "c",
+		},
+		{
+			name: "cycle",
+			joins: SqlJoins{
+				"c": SqlJoin{Table: "c", On: "true", Requires: []string{"d"}},
+				"d": SqlJoin{Table: "d", On: "true", Requires: []string{"c"}},
+			},
+		},
+		{
+			name:         "unknown join",
+			translations: SqlTranslations{"name": SqlFieldTranslation{Joins: []string{"c"}}},
+			field:        "name",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, err := CompileSqlSchema(scenario.translations, nil, WithSqlJoins(scenario.joins))
+
+			assert.ErrorIs(t, err, ErrInvalidJoin, "err should be invalid join")
+
+			if scenario.field != "" {
+				assert.Equal(t, &FieldError{Field: scenario.field, Err: ErrInvalidJoin}, err, "err should be equal")
+			}
+		})
+	}
+}
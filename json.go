@@ -0,0 +1,124 @@
+package talkback
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// sqlJSONKeyPattern matches JSON keys that are safe to embed in a JSON path.
+var sqlJSONKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// sqlJSONIdentPattern matches JSON keys that need no quoting in a JSON path.
+var sqlJSONIdentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// lookupJSONPath returns the translation of a dotted subpath of a JSON field, such as metadata.color.
+// The returned translation has its Column replaced by the extraction of the path, cast
+// to the type of the values converted by the converter of the subpath.
+func (s *SqlSchema) lookupJSONPath(field string, cond Condition) (SqlFieldTranslation, bool, error) {
+	dot := strings.Index(field, ".")
+	if dot < 0 {
+		return SqlFieldTranslation{}, false, nil
+	}
+
+	translation, ok := s.translations[field[:dot]]
+	if !ok || translation.Kind != SqlKindJSON {
+		return SqlFieldTranslation{}, false, nil
+	}
+
+	key := field[dot+1:]
+	path := strings.Split(key, ".")
+
+	for _, segment := range path {
+		if !sqlJSONKeyPattern.MatchString(segment) {
+			return SqlFieldTranslation{}, false, nil
+		}
+	}
+
+	converter, listed := translation.JSONKeys[key]
+
+	if listed {
+		translation.TypeConverter = converter
+
+		if ops, ok := s.jsonOps[field]; ok {
+			translation.Ops = ops
+		}
+	} else if translation.JSONKeyPattern == nil || !translation.JSONKeyPattern.MatchString(key) {
+		return SqlFieldTranslation{}, false, nil
+	}
+
+	var sample interface{}
+
+	if len(cond.Values) > 0 && !sliceContainsString(nullOps, cond.Op) {
+		var err error
+
+		sample, err = valueToSql(translation, cond.Values[0])
+		if err != nil {
+			return SqlFieldTranslation{}, false, &FieldError{Field: field, Op: cond.Op, Err: err}
+		}
+	}
+
+	translation.Column = jsonExtract(s.dialect, translation.Column, path, sample)
+
+	return translation, true, nil
+}
+
+// compileJSONOps computes the default Ops of the JSON keys of a translation without explicit Ops,
+// from the converter of each key instead of the converter of the JSON field.
+func (s *SqlSchema) compileJSONOps(field string, translation SqlFieldTranslation) {
+	for key, converter := range translation.JSONKeys {
+		if s.jsonOps == nil {
+			s.jsonOps = map[string][]string{}
+		}
+
+		s.jsonOps[field+"."+key] = sqlDefaultOpsOf(translation.Text, converter)
+	}
+}
+
+// jsonExtract returns the extraction of path from a JSON column, cast to the type of sample.
+func jsonExtract(dialect SqlDialect, column string, path []string, sample interface{}) string {
+	if dialect == SqlPostgres {
+		expr := column + "->>'" + path[0] + "'"
+		if len(path) > 1 {
+			expr = column + "#>>'{" + strings.Join(path, ",") + "}'"
+		}
+
+		if cast := jsonPostgresCast(sample); cast != "" {
+			return "CAST(" + expr + " AS " + cast + ")"
+		}
+
+		return expr
+	}
+
+	segments := []string{}
+
+	for _, segment := range path {
+		if !sqlJSONIdentPattern.MatchString(segment) {
+			segment = `"` + segment + `"`
+		}
+
+		segments = append(segments, segment)
+	}
+
+	expr := "JSON_EXTRACT(" + column + ", '$." + strings.Join(segments, ".") + "')"
+
+	if dialect == SqlMySQL && jsonPostgresCast(sample) == "" {
+		return "JSON_UNQUOTE(" + expr + ")"
+	}
+
+	return expr
+}
+
+// jsonPostgresCast returns the Postgres type to cast JSON text to for comparing with sample.
+func jsonPostgresCast(sample interface{}) string {
+	switch sample.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return "NUMERIC"
+	case bool:
+		return "BOOLEAN"
+	case time.Time:
+		return "TIMESTAMPTZ"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,126 @@
+package talkback
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlJSONPathWhere(t *testing.T) {
+	translations := SqlTranslations{
+		"metadata": SqlFieldTranslation{
+			Kind: SqlKindJSON,
+			JSONKeys: map[string]func(value string) (interface{}, error){
+				"color":     nil,
+				"size.unit": nil,
+				"weight":    SqlConvertInt,
+			},
+		},
+		"stats": SqlFieldTranslation{
+			Column:         "ex.stats",
+			Kind:           SqlKindJSON,
+			TypeConverter:  SqlConvertFloat,
+			JSONKeyPattern: regexp.MustCompile(`^[a-z]+$`),
+		},
+	}
+
+	query := Query{
+		Conditions: []Condition{
+			{"metadata.color", "eq", []string{"red"}},
+			{"metadata.size.unit", "ne", []string{"cm"}},
+			{"stats.price", "gt", []string{"9.5"}},
+			{"metadata.weight", "gte", []string{"10"}},
+		},
+	}
+
+	type scenarioT struct {
+		dialect   SqlDialect
+		statement string
+	}
+
+	scenarios := []scenarioT{
+		{
+			dialect:   SqlPostgres,
+			statement: `"metadata"->>'color' = ? AND "metadata"#>>'{size,unit}' != ? AND CAST("ex"."stats"->>'price' AS NUMERIC) > ? AND CAST("metadata"->>'weight' AS NUMERIC) >= ?`,
+		},
+		{
+			dialect:   SqlMySQL,
+			statement: "JSON_UNQUOTE(JSON_EXTRACT(`metadata`, '$.color')) = ? AND JSON_UNQUOTE(JSON_EXTRACT(`metadata`, '$.size.unit')) != ? AND JSON_EXTRACT(`ex`.`stats`, '$.price') > ? AND JSON_EXTRACT(`metadata`, '$.weight') >= ?",
+		},
+		{
+			dialect:   SqlSQLite,
+			statement: `JSON_EXTRACT("metadata", '$.color') = ? AND JSON_EXTRACT("metadata", '$.size.unit') != ? AND JSON_EXTRACT("ex"."stats", '$.price') > ? AND JSON_EXTRACT("metadata", '$.weight') >= ?`,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(string(scenario.dialect), func(t *testing.T) {
+			schema := MustCompileSqlSchema(translations, nil, WithSqlDialect(scenario.dialect))
+			statement, args, err := schema.ToSqlWhere(query)
+
+			assert.NoError(t, err, "error should be nil")
+			assert.Equal(t, scenario.statement, statement, "statement should be equal")
+			assert.Equal(t, []interface{}{"red", "cm", 9.5, 10}, args, "args should be equal")
+		})
+	}
+
+	for _, field := range []string{"metadata.height", "metadata.size", "stats.Price", "stats.a'b", "name.first"} {
+		t.Run(field, func(t *testing.T) {
+			_, _, err := ToSqlWhere(Query{
+				Conditions: []Condition{
+					{field, "eq", []string{"1"}},
+				},
+			}, translations)
+
+			assert.Equal(t, ErrInvalidField, err, "err should be equal")
+		})
+	}
+}
+
+func TestSqlJSONPathOps(t *testing.T) {
+	translations := SqlTranslations{
+		"metadata": SqlFieldTranslation{
+			Kind: SqlKindJSON,
+			JSONKeys: map[string]func(value string) (interface{}, error){
+				"color":  nil,
+				"weight": SqlConvertInt,
+			},
+		},
+		"stats": SqlFieldTranslation{
+			Kind:          SqlKindJSON,
+			TypeConverter: SqlConvertFloat,
+			JSONKeys: map[string]func(value string) (interface{}, error){
+				"label": SqlConvertString,
+			},
+		},
+		"tags": SqlFieldTranslation{
+			Kind: SqlKindJSON,
+			Ops:  []string{OpEq},
+			JSONKeys: map[string]func(value string) (interface{}, error){
+				"name": SqlConvertString,
+			},
+		},
+	}
+
+	type scenarioT struct {
+		name string
+		cond Condition
+		err  error
+	}
+
+	scenarios := []scenarioT{
+		{"text key of text field", Condition{"metadata.color", "contain", []string{"re"}}, nil},
+		{"number key of text field", Condition{"metadata.weight", "contain", []string{"1"}}, &FieldError{Field: "metadata.weight", Op: "contain", Err: ErrDisallowedOp}},
+		{"text key of number field", Condition{"stats.label", "contain", []string{"a"}}, nil},
+		{"explicit ops of field", Condition{"tags.name", "contain", []string{"a"}}, &FieldError{Field: "tags.name", Op: "contain", Err: ErrDisallowedOp}},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, _, err := ToSqlWhere(Query{Conditions: []Condition{scenario.cond}}, translations)
+
+			assert.Equal(t, scenario.err, err, "err should be equal")
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package talkback
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SqlConvertStep is a step of a converter pipeline, see SqlChain.
+type SqlConvertStep func(value interface{}) (interface{}, error)
+
+// SqlChain returns a TypeConverter running steps in order on the raw string value,
+// each step receiving the result of the previous one. For example:
+//
+//	SqlChain(SqlTrim, SqlConvertWith(SqlConvertInt), SqlMin(1), SqlMax(100))
+//
+// A chain of text steps only (SqlTrim, SqlLower, SqlMinLength, SqlMaxLength, SqlMatch)
+// keeps values as text and allows text operations by default.
+func SqlChain(steps ...SqlConvertStep) func(value string) (interface{}, error) {
+	if isSqlTextSteps(steps) {
+		return sqlTextChain(steps)
+	}
+
+	return func(value string) (interface{}, error) {
+		return runSqlSteps(value, steps)
+	}
+}
+
+// sqlTextChain returns a chain of text steps, told apart from other chains by its code pointer.
+func sqlTextChain(steps []SqlConvertStep) func(value string) (interface{}, error) {
+	return func(value string) (interface{}, error) {
+		return runSqlSteps(value, steps)
+	}
+}
+
+// sqlTextChainPointer is the code pointer shared by all chains of text steps.
+var sqlTextChainPointer = reflect.ValueOf(sqlTextChain(nil)).Pointer()
+
+// sqlTextStepPointers are the code pointers of steps keeping values as text.
+var sqlTextStepPointers = []uintptr{
+	reflect.ValueOf(SqlTrim).Pointer(),
+	reflect.ValueOf(SqlLower).Pointer(),
+	reflect.ValueOf(SqlMinLength(0)).Pointer(),
+	reflect.ValueOf(SqlMaxLength(0)).Pointer(),
+	reflect.ValueOf(SqlMatch(nil)).Pointer(),
+}
+
+// isSqlTextSteps returns true if all steps keep values as text.
+func isSqlTextSteps(steps []SqlConvertStep) bool {
+	for _, step := range steps {
+		pointer := reflect.ValueOf(step).Pointer()
+		text := false
+
+		for _, p := range sqlTextStepPointers {
+			if pointer == p {
+				text = true
+				break
+			}
+		}
+
+		if !text {
+			return false
+		}
+	}
+
+	return true
+}
+
+// runSqlSteps runs steps in order on value.
+func runSqlSteps(value string, steps []SqlConvertStep) (interface{}, error) {
+	var result interface{} = value
+
+	for _, step := range steps {
+		var err error
+
+		result, err = step(result)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// SqlConvertWith returns a step converting a string with a TypeConverter.
+func SqlConvertWith(converter func(value string) (interface{}, error)) SqlConvertStep {
+	return func(value interface{}) (interface{}, error) {
+		s, err := stepString(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return converter(s)
+	}
+}
+
+// SqlTrim is a step removing leading and trailing white space of a string.
+func SqlTrim(value interface{}) (interface{}, error) {
+	s, err := stepString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.TrimSpace(s), nil
+}
+
+// SqlLower is a step lowercasing a string.
+func SqlLower(value interface{}) (interface{}, error) {
+	s, err := stepString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.ToLower(s), nil
+}
+
+// SqlMin returns a step validating that a number, or a decimal string such as the
+// values of SqlConvertDecimal, is at least min. Numbers are compared exactly.
+func SqlMin(min float64) SqlConvertStep {
+	bound := floatRat(min)
+
+	return func(value interface{}) (interface{}, error) {
+		n, err := stepNumber(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if compareBound(n, bound, min) < 0 {
+			return nil, fmt.Errorf("%w: %v must be at least %v", ErrInvalidValue, value, min)
+		}
+
+		return value, nil
+	}
+}
+
+// SqlMax returns a step validating that a number, or a decimal string such as the
+// values of SqlConvertDecimal, is at most max. Numbers are compared exactly.
+func SqlMax(max float64) SqlConvertStep {
+	bound := floatRat(max)
+
+	return func(value interface{}) (interface{}, error) {
+		n, err := stepNumber(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if compareBound(n, bound, max) > 0 {
+			return nil, fmt.Errorf("%w: %v must be at most %v", ErrInvalidValue, value, max)
+		}
+
+		return value, nil
+	}
+}
+
+// SqlMinLength returns a step validating that a string has at least min characters.
+func SqlMinLength(min int) SqlConvertStep {
+	return func(value interface{}) (interface{}, error) {
+		s, err := stepString(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if utf8.RuneCountInString(s) < min {
+			return nil, fmt.Errorf("%w: %q must be at least %d characters", ErrInvalidValue, s, min)
+		}
+
+		return value, nil
+	}
+}
+
+// SqlMaxLength returns a step validating that a string has at most max characters.
+func SqlMaxLength(max int) SqlConvertStep {
+	return func(value interface{}) (interface{}, error) {
+		s, err := stepString(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if utf8.RuneCountInString(s) > max {
+			return nil, fmt.Errorf("%w: %q must be at most %d characters", ErrInvalidValue, s, max)
+		}
+
+		return value, nil
+	}
+}
+
+// SqlMatch returns a step validating that a string matches re.
+func SqlMatch(re *regexp.Regexp) SqlConvertStep {
+	return func(value interface{}) (interface{}, error) {
+		s, err := stepString(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if !re.MatchString(s) {
+			return nil, fmt.Errorf("%w: %q must match %s", ErrInvalidValue, s, re)
+		}
+
+		return value, nil
+	}
+}
+
+// SqlOneOf returns a step validating that a value is one of allowed.
+func SqlOneOf(allowed ...interface{}) SqlConvertStep {
+	return func(value interface{}) (interface{}, error) {
+		for _, a := range allowed {
+			if a == value {
+				return value, nil
+			}
+		}
+
+		return nil, fmt.Errorf("%w: %v must be one of %v", ErrInvalidValue, value, allowed)
+	}
+}
+
+// stepString returns value as a string, or an error if it is not one.
+func stepString(value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %v is not a string", ErrInvalidValue, value)
+	}
+
+	return s, nil
+}
+
+// stepNumber returns value as an exact rational number, or an error if it is not a number
+// or a decimal string.
+func stepNumber(value interface{}) (*big.Rat, error) {
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(v.Uint())), nil
+	case reflect.Float32:
+		return decimalRat(strconv.FormatFloat(v.Float(), 'g', -1, 32))
+	case reflect.Float64:
+		return decimalRat(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.String:
+		if sqlDecimalPattern.MatchString(v.String()) {
+			return decimalRat(v.String())
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %v is not a number", ErrInvalidValue, value)
+}
+
+// floatRat returns f as the rational number of its shortest decimal representation,
+// so that a bound of 0.1 equals the decimal 0.1, or nil if f is not finite.
+func floatRat(f float64) *big.Rat {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil
+	}
+
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+
+	return r
+}
+
+// compareBound compares n to a bound f and its rational number, see floatRat.
+// Infinite bounds are beyond every number and NaN bounds never fail.
+func compareBound(n *big.Rat, bound *big.Rat, f float64) int {
+	switch {
+	case bound != nil:
+		return n.Cmp(bound)
+	case math.IsInf(f, 1):
+		return -1
+	case math.IsInf(f, -1):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// decimalRat parses a decimal number, failing on infinities and NaN.
+func decimalRat(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not a number", ErrInvalidValue, s)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,125 @@
+package talkback
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlChain(t *testing.T) {
+	type scenarioT struct {
+		name      string
+		converter func(value string) (interface{}, error)
+		value     string
+		out       interface{}
+		err       error
+	}
+
+	scenarios := []scenarioT{
+		{
+			name:      "normalize",
+			converter: SqlChain(SqlTrim, SqlLower),
+			value:     "  Hello ",
+			out:       "hello",
+		},
+		{
+			name:      "bounds",
+			converter: SqlChain(SqlTrim, SqlConvertWith(SqlConvertInt), SqlMin(1), SqlMax(100)),
+			value:     " 42",
+			out:       42,
+		},
+		{
+			name:      "below min",
+			converter: SqlChain(SqlConvertWith(SqlConvertFloat), SqlMin(1)),
+			value:     "0.5",
+			err:       fmt.Errorf("%w: 0.5 must be at least 1", ErrInvalidValue),
+		},
+		{
+			name:      "above max",
+			converter: SqlChain(SqlConvertWith(SqlConvertInt), SqlMax(100)),
+			value:     "150",
+			err:       fmt.Errorf("%w: 150 must be at most 100", ErrInvalidValue),
+		},
+		{
+			name:      "decimal bounds",
+			converter: SqlChain(SqlConvertWith(SqlConvertDecimal), SqlMin(0.1), SqlMax(99.99)),
+			value:     "0.10",
+			out:       "0.10",
+		},
+		{
+			name:      "decimal above max",
+			converter: SqlChain(SqlConvertWith(SqlConvertDecimal), SqlMax(99.99)),
+			value:     "99.990000000000000001",
+			err:       fmt.Errorf("%w: 99.990000000000000001 must be at most 99.99", ErrInvalidValue),
+		},
+		{
+			name:      "exact int64",
+			converter: SqlChain(SqlConvertWith(SqlConvertInt64), SqlMax(9007199254740992)),
+			value:     "9007199254740993",
+			err:       fmt.Errorf("%w: 9007199254740993 must be at most 9.007199254740992e+15", ErrInvalidValue),
+		},
+		{
+			name:      "infinite bound",
+			converter: SqlChain(SqlConvertWith(SqlConvertInt), SqlMin(math.Inf(-1)), SqlMax(math.Inf(1))),
+			value:     "-5",
+			out:       -5,
+		},
+		{
+			name:      "not a decimal",
+			converter: SqlChain(SqlMin(0)),
+			value:     "1/2",
+			err:       fmt.Errorf("%w: 1/2 is not a number", ErrInvalidValue),
+		},
+		{
+			name:      "length",
+			converter: SqlChain(SqlMinLength(2), SqlMaxLength(4)),
+			value:     "héllo",
+			err:       fmt.Errorf("%w: %q must be at most 4 characters", ErrInvalidValue, "héllo"),
+		},
+		{
+			name:      "match",
+			converter: SqlChain(SqlMatch(regexp.MustCompile(`^[A-Z]{3}$`))),
+			value:     "usd",
+			err:       fmt.Errorf("%w: %q must match ^[A-Z]{3}$", ErrInvalidValue, "usd"),
+		},
+		{
+			name:      "one of",
+			converter: SqlChain(SqlLower, SqlOneOf("open", "closed")),
+			value:     "OPEN",
+			out:       "open",
+		},
+		{
+			name:      "not a string",
+			converter: SqlChain(SqlConvertWith(SqlConvertInt), SqlTrim),
+			value:     "1",
+			err:       fmt.Errorf("%w: 1 is not a string", ErrInvalidValue),
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			out, err := scenario.converter(scenario.value)
+
+			assert.Equal(t, scenario.out, out, "out should be equal")
+			assert.Equal(t, scenario.err, err, "err should be equal")
+		})
+	}
+
+	t.Run("query error", func(t *testing.T) {
+		_, _, err := ToSqlWhere(Query{
+			Conditions: []Condition{
+				{"price", "gt", []string{"-1"}},
+			},
+		}, SqlTranslations{
+			"price": SqlFieldTranslation{
+				TypeConverter: SqlChain(SqlConvertWith(SqlConvertInt), SqlMin(0)),
+			},
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidValue, "err should be invalid value")
+		assert.EqualError(t, err, "price_gt: invalid value: -1 must be at least 0", "err should be equal")
+	})
+}
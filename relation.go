@@ -0,0 +1,229 @@
+package talkback
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// SqlRelation is a child table whose rows can filter their parents, e.g. items of orders.
+type SqlRelation struct {
+	Table        string          // Table is the child table.
+	LocalKey     string          // LocalKey is the qualified parent column referenced by the child, e.g. orders.id.
+	ForeignKey   string          // ForeignKey is the child column referencing the parent, e.g. order_id.
+	Translations SqlTranslations // Translations are the child fields that can be filtered on.
+	Preload      string          // Preload is the model preloaded by With, empty if the relation is not preloadable.
+	Relations    SqlRelations    // Relations are the relations of the child, for nested filters and preloads.
+}
+
+// SqlRelations is a map of relation names to relations.
+type SqlRelations map[string]SqlRelation
+
+// sqlRelation is a compiled SqlRelation.
+type sqlRelation struct {
+	from   string     // from is the quoted child table.
+	join   string     // join is the condition correlating child rows to the parent row.
+	schema *SqlSchema // schema is the compiled schema of the child translations.
+}
+
+// WithSqlRelations enables filtering on child fields prefixed with the relation name,
+// such as items.sku_eq, translated to correlated EXISTS subqueries.
+// AccessPolicy rules on the relation name also apply to its child fields.
+func WithSqlRelations(relations SqlRelations) SqlSchemaOption {
+	return func(schema *SqlSchema) {
+		schema.relationConfigs = relations
+	}
+}
+
+// compileRelations validates and compiles the relations of the schema.
+func (s *SqlSchema) compileRelations() error {
+	if len(s.relationConfigs) > 0 {
+		s.relations = make(map[string]sqlRelation, len(s.relationConfigs))
+	}
+
+	for name, relation := range s.relationConfigs {
+		_, conflict := s.translations[name]
+
+		valid := !conflict && !strings.Contains(name, ".") &&
+			isSqlColumn(relation.Table) &&
+			isSqlColumn(relation.LocalKey) &&
+			isSqlColumn(relation.ForeignKey) &&
+			strings.Contains(relation.LocalKey, ".")
+
+		if !valid {
+			return &FieldError{Field: name, Err: ErrInvalidRelation}
+		}
+
+		if _, ok := s.preloadable[name]; ok && relation.Preload != "" {
+			return &FieldError{Field: name, Err: ErrInvalidPreload}
+		}
+
+		if relation.Preload != "" {
+			if s.preloadable == nil {
+				s.preloadable = SqlPreloadable{}
+			}
+
+			s.preloadable[name] = relation.Preload
+		}
+
+		foreignKey := relation.ForeignKey
+		if !strings.Contains(foreignKey, ".") {
+			foreignKey = relation.Table + "." + foreignKey
+		}
+
+		schema, err := CompileSqlSchema(qualifySqlTranslations(relation.Table, relation.Translations), nil,
+			WithSqlDialect(s.dialect),
+			WithSqlRegexGuard(s.regexGuard),
+			WithSqlRelations(relation.Relations),
+		)
+		if err != nil {
+			return relationError(name, err)
+		}
+
+		s.relations[name] = sqlRelation{
+			from:   s.dialect.quote(relation.Table),
+			join:   s.dialect.quote(foreignKey) + " = " + s.dialect.quote(relation.LocalKey),
+			schema: schema,
+		}
+	}
+
+	return nil
+}
+
+// SqlPreloadPlan is the plan of a preload, applied by an ORM adapter to the preloaded rows.
+type SqlPreloadPlan struct {
+	Name  string  // Name is the preload name used in Query.With.
+	Model string  // Model is the preloaded model.
+	Plan  SqlPlan // Plan filters, sorts and paginates the preloaded rows, empty if the preload is not a relation.
+}
+
+// toSqlPreloadPlans converts the preloads of a Query and their sub-queries to SqlPreloadPlans.
+// Sub-queries are validated against the translations of the relation of the same name.
+func (s *SqlSchema) toSqlPreloadPlans(ctx context.Context, query Query) ([]SqlPreloadPlan, error) {
+	plans := make([]SqlPreloadPlan, 0, len(query.With))
+
+	for preload := range query.WithQuery {
+		if !sliceContainsString(query.With, preload) {
+			return nil, &FieldError{Field: preload, Err: ErrInvalidPreload}
+		}
+	}
+
+	for _, preload := range query.With {
+		model, ok := s.preloadable[preload]
+		if !ok {
+			return nil, ErrInvalidPreload
+		}
+
+		sub, hasSub := query.WithQuery[preload]
+		relation, isRelation := s.relations[preload]
+
+		if hasSub && !isRelation {
+			return nil, &FieldError{Field: preload, Err: ErrInvalidPreload}
+		}
+
+		plan := SqlPreloadPlan{Name: preload, Model: model}
+
+		if isRelation {
+			var err error
+
+			plan.Plan, err = relation.schema.ToSqlPlanContext(ctx, sub)
+			if err != nil {
+				return nil, relationError(preload, err)
+			}
+		}
+
+		plans = append(plans, plan)
+	}
+
+	return plans, nil
+}
+
+// isRelationField returns true if field is prefixed with the name of a relation.
+func (s *SqlSchema) isRelationField(field string) bool {
+	dot := strings.Index(field, ".")
+	if dot < 0 {
+		return false
+	}
+
+	_, ok := s.relations[field[:dot]]
+
+	return ok
+}
+
+// relationsToSql converts the conditions of a Query on relation fields to EXISTS subqueries,
+// one per relation in order of first use, so that all its conditions match the same child row.
+func (s *SqlSchema) relationsToSql(ctx context.Context, query Query) ([]string, []interface{}, error) {
+	if len(s.relations) == 0 {
+		return nil, nil, nil
+	}
+
+	names := []string{}
+	queries := map[string]*Query{}
+
+	for _, cond := range query.Conditions {
+		if !s.isRelationField(cond.Field) {
+			continue
+		}
+
+		dot := strings.Index(cond.Field, ".")
+		name := cond.Field[:dot]
+
+		if _, ok := queries[name]; !ok {
+			names = append(names, name)
+			queries[name] = &Query{}
+		}
+
+		cond.Field = cond.Field[dot+1:]
+		queries[name].Conditions = append(queries[name].Conditions, cond)
+	}
+
+	statements := []string{}
+	args := []interface{}{}
+
+	for _, name := range names {
+		relation := s.relations[name]
+
+		where, whereArgs, err := relation.schema.ToSqlWhereContext(ctx, *queries[name])
+		if err != nil {
+			return nil, nil, relationError(name, err)
+		}
+
+		statements = append(statements, "EXISTS (SELECT 1 FROM "+relation.from+" WHERE "+relation.join+" AND "+where+")")
+		args = append(args, whereArgs...)
+	}
+
+	return statements, args, nil
+}
+
+// qualifySqlTranslations returns translations with unqualified columns qualified with table,
+// so that they are unambiguous in subqueries of the table.
+func qualifySqlTranslations(table string, translations SqlTranslations) SqlTranslations {
+	qualified := make(SqlTranslations, len(translations))
+
+	for field, translation := range translations {
+		if !translation.Raw && translation.Expr == "" {
+			column := translation.Column
+			if column == "" {
+				column = field
+			}
+
+			if !strings.Contains(column, ".") {
+				translation.Column = table + "." + column
+			}
+		}
+
+		qualified[field] = translation
+	}
+
+	return qualified
+}
+
+// relationError prefixes the field of a FieldError with the name of a relation.
+func relationError(name string, err error) error {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return &FieldError{Field: name + "." + fieldErr.Field, Op: fieldErr.Op, Err: fieldErr.Err}
+	}
+
+	return err
+}
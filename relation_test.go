@@ -0,0 +1,208 @@
+package talkback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlRelations(t *testing.T) {
+	schema := MustCompileSqlSchema(SqlTranslations{
+		"status": SqlFieldTranslation{},
+	}, nil, WithSqlRelations(SqlRelations{
+		"items": SqlRelation{
+			Table:      "order_items",
+			LocalKey:   "orders.id",
+			ForeignKey: "order_id",
+			Translations: SqlTranslations{
+				"sku": SqlFieldTranslation{},
+				"qty": SqlFieldTranslation{TypeConverter: SqlConvertInt},
+				"deleted_at": SqlFieldTranslation{
+					Scope: &SqlScope{Op: OpIsNull, Values: []string{"true"}},
+				},
+			},
+		},
+	}))
+
+	t.Run("exists", func(t *testing.T) {
+		statement, args, err := schema.ToSqlWhere(Query{
+			Conditions: []Condition{
+				{"items.sku", "eq", []string{"X"}},
+				{"status", "eq", []string{"open"}},
+				{"items.qty", "gt", []string{"2"}},
+			},
+		})
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `"status" = ? AND EXISTS (SELECT 1 FROM "order_items" WHERE "order_items"."order_id" = "orders"."id" AND "order_items"."deleted_at" IS NULL AND "order_items"."sku" = ? AND "order_items"."qty" > ?)`, statement, "statement should be equal")
+		assert.Equal(t, []interface{}{"open", "X", 2}, args, "args should be equal")
+	})
+
+	t.Run("invalid child field", func(t *testing.T) {
+		_, _, err := schema.ToSqlWhere(Query{
+			Conditions: []Condition{
+				{"items.qty", "gt", []string{"many"}},
+			},
+		})
+
+		assert.ErrorContains(t, err, "items.qty_gt: ", "err should name the field")
+
+		_, _, err = schema.ToSqlWhere(Query{
+			Conditions: []Condition{
+				{"items.price", "gt", []string{"1"}},
+			},
+		})
+
+		assert.Equal(t, ErrInvalidField, err, "err should be equal")
+	})
+
+	t.Run("unknown relation", func(t *testing.T) {
+		_, _, err := schema.ToSqlWhere(Query{
+			Conditions: []Condition{
+				{"tags.name", "eq", []string{"X"}},
+			},
+		})
+
+		assert.Equal(t, ErrInvalidField, err, "err should be equal")
+	})
+}
+
+func TestSqlRelationsInvalid(t *testing.T) {
+	type scenarioT struct {
+		name     string
+		relation string
+		config   SqlRelation
+	}
+
+	scenarios := []scenarioT{
+		{"unqualified local key", "items", SqlRelation{Table: "items", LocalKey: "id", ForeignKey: "order_id"}},
+		{"invalid table", "items", SqlRelation{Table: "items i", LocalKey: "orders.id", ForeignKey: "order_id"}},
+		{"missing foreign key", "items", SqlRelation{Table: "items", LocalKey: "orders.id"}},
+		{"conflicting name", "status", SqlRelation{Table: "status", LocalKey: "orders.id", ForeignKey: "order_id"}},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, err := CompileSqlSchema(SqlTranslations{"status": SqlFieldTranslation{}}, nil, WithSqlRelations(SqlRelations{scenario.relation: scenario.config}))
+			assert.Equal(t, &FieldError{Field: scenario.relation, Err: ErrInvalidRelation}, err, "err should be equal")
+		})
+	}
+
+	_, err := CompileSqlSchema(nil, nil, WithSqlRelations(SqlRelations{
+		"items": SqlRelation{
+			Table:        "items",
+			LocalKey:     "orders.id",
+			ForeignKey:   "order_id",
+			Translations: SqlTranslations{"sku": SqlFieldTranslation{Column: "sku sku"}},
+		},
+	}))
+
+	assert.ErrorIs(t, err, ErrInvalidColumn, "err should be invalid column")
+	assert.ErrorContains(t, err, "items.sku: ", "err should name the field")
+}
+
+func TestSqlRelationPreloads(t *testing.T) {
+	schema := MustCompileSqlSchema(SqlTranslations{
+		"status": SqlFieldTranslation{},
+	}, SqlPreloadable{"customer": "Customer"}, WithSqlRelations(SqlRelations{
+		"comments": SqlRelation{
+			Table:      "comments",
+			LocalKey:   "posts.id",
+			ForeignKey: "post_id",
+			Preload:    "Comments",
+			Translations: SqlTranslations{
+				"author":     SqlFieldTranslation{},
+				"created_at": SqlFieldTranslation{},
+			},
+			Relations: SqlRelations{
+				"likes": SqlRelation{
+					Table:        "likes",
+					LocalKey:     "comments.id",
+					ForeignKey:   "comment_id",
+					Preload:      "Likes",
+					Translations: SqlTranslations{"user": SqlFieldTranslation{}},
+				},
+			},
+		},
+	}))
+
+	t.Run("plans", func(t *testing.T) {
+		query, err := FromQueryString("with=customer&with=comments&comments.sort=-created_at&comments.limit=5&comments.author_eq=me&comments.with=likes&comments.likes.limit=1")
+		assert.NoError(t, err, "error should be nil")
+
+		plan, err := schema.ToSqlPlan(query)
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, []string{"Customer", "Comments"}, plan.Preload, "preload should be equal")
+		assert.Equal(t, []SqlPreloadPlan{
+			{Name: "customer", Model: "Customer"},
+			{
+				Name:  "comments",
+				Model: "Comments",
+				Plan: SqlPlan{
+					Where:     `"comments"."author" = ?`,
+					WhereArgs: []interface{}{"me"},
+					Order:     `"comments"."created_at" DESC`,
+					Limit:     5,
+					Preload:   []string{"Likes"},
+					Preloads: []SqlPreloadPlan{
+						{
+							Name:  "likes",
+							Model: "Likes",
+							Plan: SqlPlan{
+								WhereArgs: []interface{}{},
+								Limit:     1,
+								Preload:   []string{},
+								Preloads:  []SqlPreloadPlan{},
+							},
+						},
+					},
+				},
+			},
+		}, plan.Preloads, "preloads should be equal")
+	})
+
+	t.Run("nested filter", func(t *testing.T) {
+		statement, args, err := schema.ToSqlWhere(Query{
+			Conditions: []Condition{
+				{"comments.likes.user", "eq", []string{"me"}},
+			},
+		})
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `EXISTS (SELECT 1 FROM "comments" WHERE "comments"."post_id" = "posts"."id" AND EXISTS (SELECT 1 FROM "likes" WHERE "likes"."comment_id" = "comments"."id" AND "likes"."user" = ?))`, statement, "statement should be equal")
+		assert.Equal(t, []interface{}{"me"}, args, "args should be equal")
+	})
+
+	t.Run("invalid sub-query", func(t *testing.T) {
+		_, err := schema.ToSqlPlan(Query{
+			With: []string{"comments"},
+			WithQuery: map[string]Query{
+				"comments": {Sort: []Sort{{Field: "likes"}}},
+			},
+		})
+		assert.Equal(t, ErrInvalidField, err, "err should be equal")
+
+		_, err = schema.ToSqlPlan(Query{
+			With: []string{"customer"},
+			WithQuery: map[string]Query{
+				"customer": {Limit: 1},
+			},
+		})
+		assert.Equal(t, &FieldError{Field: "customer", Err: ErrInvalidPreload}, err, "err should be equal")
+
+		_, err = schema.ToSqlPlan(Query{
+			WithQuery: map[string]Query{
+				"comments": {Limit: 1},
+			},
+		})
+		assert.Equal(t, &FieldError{Field: "comments", Err: ErrInvalidPreload}, err, "err should be equal")
+	})
+
+	t.Run("conflicting preload", func(t *testing.T) {
+		_, err := CompileSqlSchema(nil, SqlPreloadable{"comments": "Comments"}, WithSqlRelations(SqlRelations{
+			"comments": SqlRelation{Table: "comments", LocalKey: "posts.id", ForeignKey: "post_id", Preload: "Comments"},
+		}))
+		assert.Equal(t, &FieldError{Field: "comments", Err: ErrInvalidPreload}, err, "err should be equal")
+	})
+}
@@ -0,0 +1,679 @@
+package talkback
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SqlSchema is a set of SQL translations and preloads compiled once and reused
+// across queries. It is safe for concurrent use.
+type SqlSchema struct {
+	translations SqlTranslations
+	scopes       []string
+	scoped       SqlTranslations
+	preloadable  SqlPreloadable
+	dialect      SqlDialect
+	regexGuard   func(pattern string) error
+	search       *SqlSearch
+	exprs        SqlTranslations
+	jsonOps      map[string][]string
+	defaultSort  []Sort
+	tiebreaker   string
+	policy       *AccessPolicy
+
+	relationConfigs SqlRelations
+	relations       map[string]sqlRelation
+
+	joinConfigs SqlJoins
+	joins       map[string]string
+}
+
+// SqlSchemaOption configures a SqlSchema.
+type SqlSchemaOption func(schema *SqlSchema)
+
+// WithSqlDialect sets the SQL dialect of the schema, defaults to SqlPostgres.
+func WithSqlDialect(dialect SqlDialect) SqlSchemaOption {
+	return func(schema *SqlSchema) {
+		schema.dialect = dialect
+	}
+}
+
+// WithSqlRegexGuard sets a function validating patterns of regex operations, see SqlRegexGuard.
+func WithSqlRegexGuard(guard func(pattern string) error) SqlSchemaOption {
+	return func(schema *SqlSchema) {
+		schema.regexGuard = guard
+	}
+}
+
+// CompileSqlSchema validates translations and preloadable and compiles them into a SqlSchema.
+func CompileSqlSchema(translations SqlTranslations, preloadable SqlPreloadable, options ...SqlSchemaOption) (*SqlSchema, error) {
+	return compileSqlSchema(translations, nil, preloadable, options...)
+}
+
+// compileSqlSchema is CompileSqlSchema compiling only the translations used returns true for, all if used is nil.
+func compileSqlSchema(translations SqlTranslations, used func(field string, translation SqlFieldTranslation) bool, preloadable SqlPreloadable, options ...SqlSchemaOption) (*SqlSchema, error) {
+	schema := &SqlSchema{
+		translations: SqlTranslations{},
+		dialect:      SqlPostgres,
+	}
+
+	for _, option := range options {
+		option(schema)
+	}
+
+	if !schema.dialect.valid() {
+		return nil, ErrInvalidDialect
+	}
+
+	for field, translation := range translations {
+		if used != nil && !used(field, translation) {
+			continue
+		}
+
+		if err := validateSqlExpr(field, translation); err != nil {
+			return nil, err
+		}
+
+		if err := validateSqlOps(field, translation); err != nil {
+			return nil, err
+		}
+
+		if translation.Ops == nil {
+			schema.compileJSONOps(field, translation)
+		}
+
+		translation = sanitizeSqlFieldTranslation(field, translation)
+
+		if err := schema.validateSqlFieldTranslation(field, translation); err != nil {
+			return nil, err
+		}
+
+		translation = schema.quoteSqlFieldTranslation(translation)
+
+		if translation.Expr != "" {
+			if schema.exprs == nil {
+				schema.exprs = SqlTranslations{}
+			}
+
+			schema.exprs[field] = translation
+		}
+
+		if translation.Scope != nil {
+			if schema.scoped == nil {
+				schema.scoped = SqlTranslations{}
+			}
+
+			schema.scopes = append(schema.scopes, field)
+			schema.scoped[field] = translation
+
+			continue
+		}
+
+		schema.translations[field] = translation
+	}
+
+	sort.Strings(schema.scopes)
+
+	if err := schema.validateSearch(); err != nil {
+		return nil, err
+	}
+
+	if err := schema.validateSort(); err != nil {
+		return nil, err
+	}
+
+	for preload, model := range preloadable {
+		if model == "" {
+			return nil, &FieldError{Field: preload, Err: ErrInvalidPreload}
+		}
+
+		if schema.preloadable == nil {
+			schema.preloadable = SqlPreloadable{}
+		}
+
+		schema.preloadable[preload] = model
+	}
+
+	if err := schema.compileRelations(); err != nil {
+		return nil, err
+	}
+
+	if err := schema.compileJoins(); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// MustCompileSqlSchema is like CompileSqlSchema but panics if the schema is invalid.
+func MustCompileSqlSchema(translations SqlTranslations, preloadable SqlPreloadable, options ...SqlSchemaOption) *SqlSchema {
+	schema, err := CompileSqlSchema(translations, preloadable, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return schema
+}
+
+// validateSqlOps validates the explicit Ops of a SqlFieldTranslation, default ones being always valid.
+func validateSqlOps(field string, translation SqlFieldTranslation) error {
+	for _, op := range translation.Ops {
+		if !sliceContainsString(validOps, op) {
+			return &FieldError{Field: field, Op: op, Err: ErrInvalidOp}
+		}
+
+		if sliceContainsString(arrayOps, op) && !translation.Kind.isArray() {
+			return &FieldError{Field: field, Op: op, Err: ErrInvalidKind}
+		}
+	}
+
+	return nil
+}
+
+// validateSqlFieldTranslation validates a sanitized SqlFieldTranslation.
+func (s *SqlSchema) validateSqlFieldTranslation(field string, translation SqlFieldTranslation) error {
+	if !translation.Raw && translation.Expr == "" && !isSqlColumn(translation.Column) {
+		return &FieldError{Field: field, Err: fmt.Errorf("%w: %q", ErrInvalidColumn, translation.Column)}
+	}
+
+	if !isSqlAlias(translation.Alias) {
+		return &FieldError{Field: field, Err: fmt.Errorf("%w: %q", ErrInvalidAlias, translation.Alias)}
+	}
+
+	if !sliceContainsString(validNulls, translation.Nulls) {
+		return &FieldError{Field: field, Err: fmt.Errorf("%w: %q is not a valid null placement", ErrInvalidSort, translation.Nulls)}
+	}
+
+	if translation.Collation != "" && !sqlCollationPattern.MatchString(translation.Collation) {
+		return &FieldError{Field: field, Err: fmt.Errorf("%w: %q is not a valid collation", ErrInvalidSort, translation.Collation)}
+	}
+
+	if err := validateArray(s.dialect, field, translation); err != nil {
+		return err
+	}
+
+	if err := validateEnum(s.dialect, field, translation.Enum); err != nil {
+		return err
+	}
+
+	if translation.Scope == nil {
+		return nil
+	}
+
+	op := translation.Scope.op()
+
+	if !sliceContainsString(validOps, op) {
+		return &FieldError{Field: field, Op: op, Err: ErrInvalidOp}
+	}
+
+	if translation.Scope.Func != nil {
+		return nil
+	}
+
+	if len(translation.Scope.Values) == 0 {
+		return &FieldError{Field: field, Op: op, Err: ErrInvalidScope}
+	}
+
+	if _, _, err := conditionToSql(s.dialect, translation, translation.Scope.condition(field, translation.Scope.Values)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// quoteSqlFieldTranslation quotes the column and alias of a validated SqlFieldTranslation.
+func (s *SqlSchema) quoteSqlFieldTranslation(translation SqlFieldTranslation) SqlFieldTranslation {
+	if !translation.Raw && translation.Expr == "" {
+		translation.Column = s.dialect.quote(translation.Column)
+	}
+
+	translation.Alias = s.dialect.quote(translation.Alias)
+
+	return translation
+}
+
+// ToSqlWhere converts a Query to a SQL WHERE statement.
+func (s *SqlSchema) ToSqlWhere(query Query) (string, []interface{}, error) {
+	return s.ToSqlWhereContext(context.Background(), query)
+}
+
+// ToSqlWhereContext converts a Query to a SQL WHERE statement, including scope conditions computed from ctx.
+// It fails with ErrAggregateCondition when a condition is on an aggregate field, see ToSqlPlan.
+func (s *SqlSchema) ToSqlWhereContext(ctx context.Context, query Query) (string, []interface{}, error) {
+	if err := s.authorize(ctx, query); err != nil {
+		return "", nil, err
+	}
+
+	for _, cond := range query.Conditions {
+		if s.isAggregateField(cond.Field) {
+			return "", nil, &FieldError{Field: cond.Field, Op: cond.Op, Err: ErrAggregateCondition}
+		}
+	}
+
+	return s.whereToSql(ctx, query)
+}
+
+// whereToSql converts a Query to a SQL WHERE statement, leaving conditions on aggregate fields to HAVING.
+func (s *SqlSchema) whereToSql(ctx context.Context, query Query) (string, []interface{}, error) {
+	statements, args, err := s.scopesToSql(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	conditions, condArgs, err := s.conditionsToSql(query, false)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(statements) == 0 && condArgs != nil {
+		statements, args = conditions, condArgs
+	} else {
+		statements = append(statements, conditions...)
+		args = append(args, condArgs...)
+	}
+
+	relations, relationArgs, err := s.relationsToSql(ctx, query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	statements = append(statements, relations...)
+	args = append(args, relationArgs...)
+
+	search, searchArgs, err := s.searchToSql(query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if search != "" {
+		statements = append(statements, search)
+		args = append(args, searchArgs...)
+	}
+
+	statement, args := s.expandExprs(strings.Join(statements, " AND "), args)
+
+	return statement, args, nil
+}
+
+// ToSqlHaving converts the conditions of a Query on aggregate fields to a SQL HAVING statement.
+func (s *SqlSchema) ToSqlHaving(query Query) (string, []interface{}, error) {
+	statements, args, err := s.conditionsToSql(query, true)
+	if err != nil || len(statements) == 0 {
+		return "", nil, err
+	}
+
+	statement, args := s.expandExprs(strings.Join(statements, " AND "), args)
+
+	return statement, args, nil
+}
+
+// conditionsToSql converts the conditions of a Query on aggregate or non-aggregate fields to SQL statements.
+func (s *SqlSchema) conditionsToSql(query Query, aggregate bool) ([]string, []interface{}, error) {
+	var statements []string
+	var args []interface{}
+
+	for _, cond := range query.Conditions {
+		translation, ok := s.translations[cond.Field]
+		if !ok && s.isRelationField(cond.Field) {
+			continue
+		}
+
+		if !ok {
+			var err error
+
+			translation, ok, err = s.lookupJSONPath(cond.Field, cond)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if !ok {
+			return nil, nil, ErrInvalidField
+		}
+
+		if translation.Aggregate != aggregate {
+			continue
+		}
+
+		if !translation.Capabilities.Has(SqlFilterable) {
+			return nil, nil, &FieldError{Field: cond.Field, Err: ErrNotFilterable}
+		}
+
+		if !sliceContainsString(translation.Ops, cond.Op) {
+			return nil, nil, &FieldError{Field: cond.Field, Op: cond.Op, Err: ErrDisallowedOp}
+		}
+
+		if sliceContainsString(regexOps, cond.Op) && s.regexGuard != nil {
+			for _, pattern := range cond.Values {
+				if err := s.regexGuard(pattern); err != nil {
+					return nil, nil, &FieldError{Field: cond.Field, Op: cond.Op, Err: err}
+				}
+			}
+		}
+
+		if translation.Aggregate && translation.UseAlias {
+			translation.Column = translation.Alias
+		}
+
+		statement, condArgs, err := conditionToSql(s.dialect, translation, cond)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if statements == nil {
+			statements = make([]string, 0, len(query.Conditions))
+			args = make([]interface{}, 0, len(query.Conditions))
+		}
+
+		statements = append(statements, statement)
+		args = append(args, condArgs...)
+	}
+
+	return statements, args, nil
+}
+
+// ToSqlSelect converts a Query to a SQL SELECT statement.
+func (s *SqlSchema) ToSqlSelect(query Query) (string, error) {
+	fields, err := s.ToSqlSelectSlice(query)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(fields, ", "), nil
+}
+
+// ToSqlSelectSlice converts a Query to a slice of SQL SELECT statements.
+// It fails with ErrExprArgs when an expression field has arguments, see ToSqlPlan.
+func (s *SqlSchema) ToSqlSelectSlice(query Query) ([]string, error) {
+	return withoutExprArgs(s.selectToSql(query))
+}
+
+// selectToSql converts a Query to a slice of SQL SELECT statements and their arguments.
+func (s *SqlSchema) selectToSql(query Query) ([]string, []interface{}, error) {
+	fields := make([]string, 0, len(query.Group)+len(query.Accumulator))
+	var args []interface{}
+
+	for _, selects := range [2][]string{query.Group, query.Accumulator} {
+		for _, field := range selects {
+			translation, ok := s.translations[field]
+			if !ok {
+				return nil, nil, ErrInvalidField
+			}
+
+			if !translation.Capabilities.Has(SqlSelectable) {
+				return nil, nil, &FieldError{Field: field, Err: ErrNotSelectable}
+			}
+
+			col := translation.Column
+			if translation.Alias != translation.Column {
+				col = col + " AS " + translation.Alias
+			}
+
+			col, colArgs := s.expandExprs(col, nil)
+			fields = append(fields, col)
+			args = append(args, colArgs...)
+		}
+	}
+
+	return fields, args, nil
+}
+
+// ToSqlGroup converts a Query to a SQL GROUP BY statement.
+func (s *SqlSchema) ToSqlGroup(query Query) (string, error) {
+	fields, err := s.ToSqlGroupSlice(query)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(fields, ", "), nil
+}
+
+// ToSqlGroupSlice converts a Query to a slice of SQL GROUP BY statements.
+// It fails with ErrExprArgs when an expression field has arguments, see ToSqlPlan.
+func (s *SqlSchema) ToSqlGroupSlice(query Query) ([]string, error) {
+	return withoutExprArgs(s.groupToSql(query))
+}
+
+// groupToSql converts a Query to a slice of SQL GROUP BY statements and their arguments.
+func (s *SqlSchema) groupToSql(query Query) ([]string, []interface{}, error) {
+	fields := make([]string, 0, len(query.Group))
+	var args []interface{}
+
+	for _, field := range query.Group {
+		translation, ok := s.translations[field]
+		if !ok {
+			return nil, nil, ErrInvalidField
+		}
+
+		if !translation.Capabilities.Has(SqlGroupable) {
+			return nil, nil, &FieldError{Field: field, Err: ErrNotGroupable}
+		}
+
+		col, colArgs := s.expandExprs(translation.Column, nil)
+		fields = append(fields, col)
+		args = append(args, colArgs...)
+	}
+
+	return fields, args, nil
+}
+
+// ToSqlOrderBy converts a Query to a SQL ORDER BY statement.
+func (s *SqlSchema) ToSqlOrderBy(query Query) (string, error) {
+	fields, err := s.ToSqlOrderBySlice(query)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(fields, ", "), nil
+}
+
+// ToSqlOrderBySlice converts a Query to a slice of SQL ORDER BY statements.
+// It fails with ErrExprArgs when an expression field has arguments, see ToSqlPlan.
+func (s *SqlSchema) ToSqlOrderBySlice(query Query) ([]string, error) {
+	return withoutExprArgs(s.orderToSql(query))
+}
+
+// orderToSql converts a Query to a slice of SQL ORDER BY statements and their arguments.
+func (s *SqlSchema) orderToSql(query Query) ([]string, []interface{}, error) {
+	sorts := s.sortsOf(query)
+	fields := make([]string, 0, len(sorts))
+	var args []interface{}
+
+	for _, field := range sorts {
+		translation, ok := s.translations[field.Field]
+		if !ok {
+			return nil, nil, ErrInvalidField
+		}
+
+		if !translation.Capabilities.Has(SqlSortable) {
+			return nil, nil, &FieldError{Field: field.Field, Err: ErrNotSortable}
+		}
+
+		nulls := field.Nulls
+		if nulls == "" {
+			nulls = translation.Nulls
+		}
+
+		if !sliceContainsString(validNulls, nulls) {
+			return nil, nil, &FieldError{Field: field.Field, Err: fmt.Errorf("%w: %q is not a valid null placement", ErrInvalidSort, nulls)}
+		}
+
+		col := translation.Column
+		if translation.UseAlias {
+			col = translation.Alias
+		}
+
+		key := col
+		if len(translation.Enum) > 0 {
+			key = enumOrder(s.dialect, col, translation.Enum)
+		}
+
+		if translation.Collation != "" {
+			key = key + " COLLATE " + s.dialect.quotePart(translation.Collation)
+		}
+
+		col, colArgs := s.expandExprs(s.dialect.orderBy(col, key, field.Reverse, nulls), nil)
+		fields = append(fields, col)
+		args = append(args, colArgs...)
+	}
+
+	return fields, args, nil
+}
+
+// ToSqlPreload converts a Query to a SQL preload statement.
+func (s *SqlSchema) ToSqlPreload(query Query) ([]string, error) {
+	preloads := make([]string, 0, len(query.With))
+
+	for _, preload := range query.With {
+		model, ok := s.preloadable[preload]
+		if !ok {
+			return nil, ErrInvalidPreload
+		}
+
+		preloads = append(preloads, model)
+	}
+
+	return preloads, nil
+}
+
+// ToSql converts a Query to a SQL SELECT query on table.
+func (s *SqlSchema) ToSql(table string, query Query) (string, []interface{}, error) {
+	return s.ToSqlContext(context.Background(), table, query)
+}
+
+// ToSqlContext converts a Query to a SQL SELECT query on table, including scope conditions computed from ctx.
+func (s *SqlSchema) ToSqlContext(ctx context.Context, table string, query Query) (string, []interface{}, error) {
+	if err := s.authorize(ctx, query); err != nil {
+		return "", nil, err
+	}
+
+	plan, err := s.toSqlPlan(ctx, query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sql := "SELECT " + plan.Select + " FROM " + table
+
+	if plan.Joins != "" {
+		sql += " " + plan.Joins
+	}
+
+	sql += " WHERE " + plan.Where +
+		" GROUP BY " + plan.Group
+
+	if plan.Having != "" {
+		sql += " HAVING " + plan.Having
+	}
+
+	sql += " ORDER BY " + plan.Order +
+		" LIMIT " + strconv.Itoa(plan.Limit) +
+		" OFFSET " + strconv.Itoa(plan.Offset)
+
+	args := []interface{}{}
+	args = append(args, plan.SelectArgs...)
+	args = append(args, plan.WhereArgs...)
+	args = append(args, plan.GroupArgs...)
+	args = append(args, plan.HavingArgs...)
+	args = append(args, plan.OrderArgs...)
+
+	return sql, args, nil
+}
+
+// ToSqlPlan converts a Query to a SqlPlan.
+func (s *SqlSchema) ToSqlPlan(query Query) (SqlPlan, error) {
+	return s.ToSqlPlanContext(context.Background(), query)
+}
+
+// ToSqlPlanContext converts a Query to a SqlPlan, including scope conditions computed from ctx.
+// Preload sub-queries are authorized by the policy of the schema, see AccessPolicy.Authorize.
+func (s *SqlSchema) ToSqlPlanContext(ctx context.Context, query Query) (SqlPlan, error) {
+	if err := s.authorize(ctx, query); err != nil {
+		return SqlPlan{}, err
+	}
+
+	plan, err := s.toSqlPlan(ctx, query)
+	if err != nil {
+		return SqlPlan{}, err
+	}
+
+	plan.Preload, err = s.ToSqlPreload(query)
+	if err != nil {
+		return SqlPlan{}, err
+	}
+
+	plan.Preloads, err = s.toSqlPreloadPlans(ctx, query)
+	if err != nil {
+		return SqlPlan{}, err
+	}
+
+	return plan, nil
+}
+
+// toSqlPlan converts a Query to a SqlPlan without preloads.
+func (s *SqlSchema) toSqlPlan(ctx context.Context, query Query) (SqlPlan, error) {
+	cselect, cselectargs, err := s.selectToSql(query)
+	if err != nil {
+		return SqlPlan{}, err
+	}
+
+	cwhere, cwhereargs, err := s.whereToSql(ctx, query)
+	if err != nil {
+		return SqlPlan{}, err
+	}
+
+	cgroup, cgroupargs, err := s.groupToSql(query)
+	if err != nil {
+		return SqlPlan{}, err
+	}
+
+	chaving, chavingargs, err := s.ToSqlHaving(query)
+	if err != nil {
+		return SqlPlan{}, err
+	}
+
+	corder, corderargs, err := s.orderToSql(query)
+	if err != nil {
+		return SqlPlan{}, err
+	}
+
+	crank, crankargs, err := s.ToSqlSearchRank(query)
+	if err != nil {
+		return SqlPlan{}, err
+	}
+
+	if crank != "" {
+		corder = append([]string{crank}, corder...)
+		corderargs = append(crankargs, corderargs...)
+	}
+
+	cjoins := s.ToSqlJoin(query)
+
+	climit, err := ToSqlLimit(query)
+	if err != nil {
+		return SqlPlan{}, err
+	}
+
+	coffset, err := ToSqlOffset(query)
+	if err != nil {
+		return SqlPlan{}, err
+	}
+
+	return SqlPlan{
+		Select:     strings.Join(cselect, ", "),
+		SelectArgs: cselectargs,
+		Joins:      cjoins,
+		Where:      cwhere,
+		WhereArgs:  cwhereargs,
+		Group:      strings.Join(cgroup, ", "),
+		GroupArgs:  cgroupargs,
+		Having:     chaving,
+		HavingArgs: chavingargs,
+		Order:      strings.Join(corder, ", "),
+		OrderArgs:  corderargs,
+		Limit:      climit,
+		Offset:     coffset,
+	}, nil
+}
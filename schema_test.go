@@ -0,0 +1,202 @@
+package talkback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileSqlSchema(t *testing.T) {
+	type scenarioT struct {
+		name         string
+		translations SqlTranslations
+		preloadable  SqlPreloadable
+		err          error
+	}
+
+	scenarios := []scenarioT{
+		{
+			name: "valid schema",
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					Ops: []string{OpEq},
+				},
+				"field2": SqlFieldTranslation{
+					TypeConverter: SqlConvertInt,
+					Scope: &SqlScope{
+						Values: []string{"1"},
+					},
+				},
+			},
+			preloadable: SqlPreloadable{
+				"field3": "Field3",
+			},
+			err: nil,
+		},
+		{
+			name: "unknown op",
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					Ops: []string{"like"},
+				},
+			},
+			err: &FieldError{Field: "field1", Op: "like", Err: ErrInvalidOp},
+		},
+		{
+			name: "scope without values",
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					Scope: &SqlScope{},
+				},
+			},
+			err: &FieldError{Field: "field1", Op: "eq", Err: ErrInvalidScope},
+		},
+		{
+			name: "empty preload model",
+			preloadable: SqlPreloadable{
+				"field1": "",
+			},
+			err: &FieldError{Field: "field1", Err: ErrInvalidPreload},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, err := CompileSqlSchema(scenario.translations, scenario.preloadable)
+
+			assert.Equal(t, scenario.err, err, "err should be equal")
+		})
+	}
+
+	t.Run("unconvertible scope value", func(t *testing.T) {
+		_, err := CompileSqlSchema(SqlTranslations{
+			"field1": SqlFieldTranslation{
+				TypeConverter: SqlConvertInt,
+				Scope: &SqlScope{
+					Values: []string{"one"},
+				},
+			},
+		}, nil)
+
+		assert.ErrorContains(t, err, "field1_eq: ", "err should name the field")
+	})
+}
+
+func TestSqlSchemaToSqlPlan(t *testing.T) {
+	schema := MustCompileSqlSchema(SqlTranslations{
+		"field1": SqlFieldTranslation{
+			Alias: "alias1",
+		},
+		"field2": SqlFieldTranslation{
+			Column: "ex.field2",
+		},
+	}, SqlPreloadable{
+		"field3": "Field3",
+	})
+
+	query := Query{
+		Conditions: []Condition{
+			{"field1", "eq", []string{"value1"}},
+			{"field2", "ne", []string{"value2"}},
+		},
+		With:  []string{"field3"},
+		Group: []string{"field1", "field2"},
+		Sort: []Sort{
+			{Field: "field1", Reverse: false},
+			{Field: "field2", Reverse: true},
+		},
+		Limit: 10,
+		Skip:  10,
+	}
+
+	plan, err := schema.ToSqlPlan(query)
+
+	assert.NoError(t, err, "error should be nil")
+	assert.Equal(t, SqlPlan{
+		Select:    `"field1" AS "alias1", "ex"."field2" AS "field2"`,
+		Where:     `"field1" = ? AND "ex"."field2" != ?`,
+		WhereArgs: []interface{}{"value1", "value2"},
+		Group:     `"field1", "ex"."field2"`,
+		Order:     `"field1" ASC, "ex"."field2" DESC`,
+		Limit:     10,
+		Offset:    10,
+		Preload:   []string{"Field3"},
+		Preloads:  []SqlPreloadPlan{{Name: "field3", Model: "Field3"}},
+	}, plan, "plan should be equal")
+}
+
+var benchmarkTranslations = SqlTranslations{
+	"field1": SqlFieldTranslation{TypeConverter: SqlConvertString},
+	"field2": SqlFieldTranslation{Column: "ex.field2", TypeConverter: SqlConvertInt},
+	"field3": SqlFieldTranslation{Alias: "alias3", TypeConverter: SqlConvertFloat},
+	"field4": SqlFieldTranslation{TypeConverter: SqlConvertBool},
+	"field5": SqlFieldTranslation{TypeConverter: SqlConvertDate},
+	"field6": SqlFieldTranslation{TypeConverter: SqlConvertISO8601},
+	"field7": SqlFieldTranslation{},
+	"field8": SqlFieldTranslation{},
+}
+
+var benchmarkPreloadable = SqlPreloadable{
+	"field9": "Field9",
+}
+
+var benchmarkQuery = Query{
+	Conditions: []Condition{
+		{"field1", "eq", []string{"value1"}},
+		{"field2", "gt", []string{"12"}},
+	},
+	With:        []string{"field9"},
+	Group:       []string{"field1", "field2"},
+	Accumulator: []string{"field3"},
+	Sort: []Sort{
+		{Field: "field1", Reverse: false},
+		{Field: "field2", Reverse: true},
+	},
+	Limit: 10,
+}
+
+func BenchmarkToSqlPlan(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := ToSqlPlan(benchmarkQuery, benchmarkTranslations, benchmarkPreloadable); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSqlSchemaToSqlPlan(b *testing.B) {
+	schema := MustCompileSqlSchema(benchmarkTranslations, benchmarkPreloadable)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := schema.ToSqlPlan(benchmarkQuery); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkToSqlWhere(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, err := ToSqlWhere(benchmarkQuery, benchmarkTranslations); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSqlSchemaToSqlWhere(b *testing.B) {
+	schema := MustCompileSqlSchema(benchmarkTranslations, benchmarkPreloadable)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, err := schema.ToSqlWhere(benchmarkQuery); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package talkback
+
+import "context"
+
+// SqlScope is a mandatory condition on a field. Scoped fields are always
+// applied by ToSqlWhereContext and can not be seen or used by clients.
+type SqlScope struct {
+	Op     string                                      // Op is the operation to perform, defaults to eq.
+	Values []string                                    // Values is a static list of values to filter on.
+	Func   func(ctx context.Context) ([]string, error) // Func computes the values from ctx, overriding Values.
+}
+
+// op returns the operation of the scope.
+func (s SqlScope) op() string {
+	if s.Op == "" {
+		return OpEq
+	}
+
+	return s.Op
+}
+
+// values returns the values of the scope for ctx.
+func (s SqlScope) values(ctx context.Context) ([]string, error) {
+	if s.Func != nil {
+		return s.Func(ctx)
+	}
+
+	return s.Values, nil
+}
+
+// condition returns the scope as a Condition on field.
+func (s SqlScope) condition(field string, values []string) Condition {
+	return Condition{
+		Field:  field,
+		Op:     s.op(),
+		Values: values,
+	}
+}
+
+// scopesToSql converts the scoped fields of the schema to SQL statements and arguments.
+func (s *SqlSchema) scopesToSql(ctx context.Context) ([]string, []interface{}, error) {
+	statements := []string{}
+	args := []interface{}{}
+
+	for _, field := range s.scopes {
+		translation := s.scoped[field]
+
+		values, err := translation.Scope.values(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cond := translation.Scope.condition(field, values)
+
+		if len(values) == 0 {
+			return nil, nil, &FieldError{Field: field, Op: cond.Op, Err: ErrInvalidScope}
+		}
+
+		statement, condArgs, err := conditionToSql(s.dialect, translation, cond)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		statements = append(statements, statement)
+		args = append(args, condArgs...)
+	}
+
+	return statements, args, nil
+}
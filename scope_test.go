@@ -0,0 +1,90 @@
+package talkback
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantContextKey struct{}
+
+func TestToSqlWhereContextScope(t *testing.T) {
+	translations := SqlTranslations{
+		"name": SqlFieldTranslation{},
+		"tenant": SqlFieldTranslation{
+			Column:        "tenant_id",
+			TypeConverter: SqlConvertInt,
+			Scope: &SqlScope{
+				Func: func(ctx context.Context) ([]string, error) {
+					tenant, ok := ctx.Value(tenantContextKey{}).(string)
+					if !ok {
+						return nil, errors.New("missing tenant")
+					}
+
+					return []string{tenant}, nil
+				},
+			},
+		},
+		"deleted": SqlFieldTranslation{
+			Column: "deleted_at",
+			Scope: &SqlScope{
+				Op:     OpIsNull,
+				Values: []string{"true"},
+			},
+		},
+	}
+
+	ctx := context.WithValue(context.Background(), tenantContextKey{}, "7")
+
+	t.Run("applies scopes", func(t *testing.T) {
+		query := Query{
+			Conditions: []Condition{
+				{"name", "eq", []string{"value1"}},
+			},
+		}
+
+		statement, args, err := ToSqlWhereContext(ctx, query, translations)
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `"deleted_at" IS NULL AND "tenant_id" = ? AND "name" = ?`, statement, "statement should be equal")
+		assert.Equal(t, []interface{}{7, "value1"}, args, "args should be equal")
+	})
+
+	t.Run("applies scopes without conditions", func(t *testing.T) {
+		statement, args, err := ToSqlWhereContext(ctx, Query{}, translations)
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `"deleted_at" IS NULL AND "tenant_id" = ?`, statement, "statement should be equal")
+		assert.Equal(t, []interface{}{7}, args, "args should be equal")
+	})
+
+	t.Run("hides scoped fields", func(t *testing.T) {
+		query := Query{
+			Conditions: []Condition{
+				{"tenant", "eq", []string{"8"}},
+			},
+		}
+
+		_, _, err := ToSqlWhereContext(ctx, query, translations)
+		assert.Equal(t, ErrInvalidField, err, "err should be equal")
+
+		_, err = ToSqlSelect(Query{Accumulator: []string{"tenant"}}, translations)
+		assert.Equal(t, ErrInvalidField, err, "err should be equal")
+	})
+
+	t.Run("fails without scope values", func(t *testing.T) {
+		_, _, err := ToSqlWhere(Query{}, translations)
+
+		assert.EqualError(t, err, "missing tenant", "err should be equal")
+	})
+
+	t.Run("applies scopes to plan", func(t *testing.T) {
+		plan, err := ToSqlPlanContext(ctx, Query{}, translations, SqlPreloadable{})
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `"deleted_at" IS NULL AND "tenant_id" = ?`, plan.Where, "where should be equal")
+		assert.Equal(t, []interface{}{7}, plan.WhereArgs, "args should be equal")
+	})
+}
@@ -0,0 +1,133 @@
+package talkback
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SqlSearch configures the full-text search of Query.Search.
+type SqlSearch struct {
+	Fields   []string // Fields is a list of translated fields to search in.
+	Config   string   // Config is the Postgres text search configuration, defaults to simple.
+	Rank     bool     // Rank orders results by relevance before any other sort.
+	Fallback bool     // Fallback searches with OR'ed case insensitive LIKEs, always used by SqlSQLite.
+}
+
+// sqlSearchConfigPattern matches valid Postgres text search configurations.
+var sqlSearchConfigPattern = regexp.MustCompile(`^[a-z_]+$`)
+
+// WithSqlSearch enables full-text search on the schema, Query.Search being ignored without it.
+func WithSqlSearch(search SqlSearch) SqlSchemaOption {
+	return func(schema *SqlSchema) {
+		if search.Config == "" {
+			search.Config = "simple"
+		}
+
+		schema.search = &search
+	}
+}
+
+// validateSearch validates the search fields and configuration of the schema.
+func (s *SqlSchema) validateSearch() error {
+	if s.search == nil {
+		return nil
+	}
+
+	if len(s.search.Fields) == 0 || !sqlSearchConfigPattern.MatchString(s.search.Config) {
+		return ErrInvalidSearch
+	}
+
+	for _, field := range s.search.Fields {
+		if _, ok := s.translations[field]; !ok {
+			return &FieldError{Field: field, Err: ErrInvalidSearch}
+		}
+	}
+
+	return nil
+}
+
+// searchColumns returns the columns of the search fields.
+func (s *SqlSchema) searchColumns() []string {
+	columns := []string{}
+
+	for _, field := range s.search.Fields {
+		columns = append(columns, s.translations[field].Column)
+	}
+
+	return columns
+}
+
+// searchFallback returns true if the search uses LIKEs instead of full-text search.
+func (s *SqlSchema) searchFallback() bool {
+	return s.search.Fallback || s.dialect == SqlSQLite
+}
+
+// searchMatch returns the full-text match expression of the search, without fallback.
+func (s *SqlSchema) searchMatch() string {
+	columns := s.searchColumns()
+
+	if s.dialect == SqlMySQL {
+		return "MATCH (" + strings.Join(columns, ", ") + ") AGAINST (? IN NATURAL LANGUAGE MODE)"
+	}
+
+	return s.searchVector() + " @@ " + s.searchTsQuery()
+}
+
+// searchVector returns the Postgres text search vector of the search columns.
+func (s *SqlSchema) searchVector() string {
+	texts := []string{}
+
+	for _, column := range s.searchColumns() {
+		texts = append(texts, "COALESCE("+s.dialect.castAsText(column)+", '')")
+	}
+
+	return "to_tsvector('" + s.search.Config + "', " + strings.Join(texts, " || ' ' || ") + ")"
+}
+
+// searchTsQuery returns the Postgres text search query of the search value.
+func (s *SqlSchema) searchTsQuery() string {
+	return "plainto_tsquery('" + s.search.Config + "', ?)"
+}
+
+// searchToSql converts Query.Search to a SQL statement and arguments.
+// Search is ignored when the schema has no search configured, see WithSqlSearch.
+func (s *SqlSchema) searchToSql(query Query) (string, []interface{}, error) {
+	if query.Search == "" || s.search == nil {
+		return "", nil, nil
+	}
+
+	if s.searchFallback() {
+		statements := []string{}
+		args := []interface{}{}
+
+		for _, column := range s.searchColumns() {
+			statements = append(statements, s.dialect.like(column, true, false))
+			args = append(args, "%"+query.Search+"%")
+		}
+
+		return "(" + strings.Join(statements, " OR ") + ")", args, nil
+	}
+
+	return s.searchMatch(), []interface{}{query.Search}, nil
+}
+
+// ToSqlSearchRank converts Query.Search to a SQL ORDER BY statement by relevance.
+// It returns an empty statement when search or ranking is disabled or there is nothing to rank.
+func (s *SqlSchema) ToSqlSearchRank(query Query) (string, []interface{}, error) {
+	if query.Search == "" || s.search == nil {
+		return "", nil, nil
+	}
+
+	if !s.search.Rank || s.searchFallback() {
+		return "", nil, nil
+	}
+
+	rank := "ts_rank(" + s.searchVector() + ", " + s.searchTsQuery() + ") DESC"
+	if s.dialect == SqlMySQL {
+		rank = s.searchMatch() + " DESC"
+	}
+
+	rank, args := s.expandExprs(rank, []interface{}{query.Search})
+
+	return rank, args, nil
+}
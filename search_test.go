@@ -0,0 +1,106 @@
+package talkback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlSchemaSearch(t *testing.T) {
+	translations := SqlTranslations{
+		"field1": SqlFieldTranslation{},
+		"field2": SqlFieldTranslation{
+			Column: "ex.field2",
+		},
+	}
+
+	query := Query{
+		Conditions: []Condition{
+			{"field1", "eq", []string{"value1"}},
+		},
+		Sort: []Sort{
+			{Field: "field1", Reverse: false},
+		},
+		Search: "hello world",
+	}
+
+	type scenarioT struct {
+		name      string
+		options   []SqlSchemaOption
+		where     string
+		whereArgs []interface{}
+		order     string
+		orderArgs []interface{}
+	}
+
+	scenarios := []scenarioT{
+		{
+			name: "postgres",
+			options: []SqlSchemaOption{
+				WithSqlSearch(SqlSearch{Fields: []string{"field1", "field2"}, Rank: true}),
+			},
+			where:     `"field1" = ? AND to_tsvector('simple', COALESCE(CAST("field1" AS TEXT), '') || ' ' || COALESCE(CAST("ex"."field2" AS TEXT), '')) @@ plainto_tsquery('simple', ?)`,
+			whereArgs: []interface{}{"value1", "hello world"},
+			order:     `ts_rank(to_tsvector('simple', COALESCE(CAST("field1" AS TEXT), '') || ' ' || COALESCE(CAST("ex"."field2" AS TEXT), '')), plainto_tsquery('simple', ?)) DESC, "field1" ASC`,
+			orderArgs: []interface{}{"hello world"},
+		},
+		{
+			name: "mysql",
+			options: []SqlSchemaOption{
+				WithSqlDialect(SqlMySQL),
+				WithSqlSearch(SqlSearch{Fields: []string{"field1", "field2"}, Rank: true}),
+			},
+			where:     "`field1` = ? AND MATCH (`field1`, `ex`.`field2`) AGAINST (? IN NATURAL LANGUAGE MODE)",
+			whereArgs: []interface{}{"value1", "hello world"},
+			order:     "MATCH (`field1`, `ex`.`field2`) AGAINST (? IN NATURAL LANGUAGE MODE) DESC, `field1` ASC",
+			orderArgs: []interface{}{"hello world"},
+		},
+		{
+			name: "fallback",
+			options: []SqlSchemaOption{
+				WithSqlSearch(SqlSearch{Fields: []string{"field1", "field2"}, Rank: true, Fallback: true}),
+			},
+			where:     `"field1" = ? AND (CAST("field1" AS TEXT) ILIKE ? OR CAST("ex"."field2" AS TEXT) ILIKE ?)`,
+			whereArgs: []interface{}{"value1", "%hello world%", "%hello world%"},
+			order:     `"field1" ASC`,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			schema := MustCompileSqlSchema(translations, nil, scenario.options...)
+			plan, err := schema.ToSqlPlan(query)
+
+			assert.NoError(t, err, "error should be nil")
+			assert.Equal(t, scenario.where, plan.Where, "where should be equal")
+			assert.Equal(t, scenario.whereArgs, plan.WhereArgs, "where args should be equal")
+			assert.Equal(t, scenario.order, plan.Order, "order should be equal")
+			assert.Equal(t, scenario.orderArgs, plan.OrderArgs, "order args should be equal")
+		})
+	}
+
+	t.Run("without search configured", func(t *testing.T) {
+		where, args, err := ToSqlWhere(query, translations)
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `"field1" = ?`, where, "where should be equal")
+		assert.Equal(t, []interface{}{"value1"}, args, "args should be equal")
+	})
+
+	t.Run("from query string without search configured", func(t *testing.T) {
+		parsed, err := FromQueryString("field1_eq=value1&q=hello")
+		assert.NoError(t, err, "error should be nil")
+
+		plan, err := ToSqlPlan(parsed, translations, nil)
+
+		assert.NoError(t, err, "error should be nil")
+		assert.Equal(t, `"field1" = ?`, plan.Where, "where should be equal")
+		assert.Equal(t, "", plan.Order, "order should be equal")
+	})
+
+	t.Run("with unknown search field", func(t *testing.T) {
+		_, err := CompileSqlSchema(translations, nil, WithSqlSearch(SqlSearch{Fields: []string{"field3"}}))
+
+		assert.Equal(t, &FieldError{Field: "field3", Err: ErrInvalidSearch}, err, "err should be equal")
+	})
+}
@@ -0,0 +1,76 @@
+package talkback
+
+// WithSqlDefaultSort sets the sort used when a Query has none.
+// When grouping, only the sorts on grouped fields are used.
+func WithSqlDefaultSort(sort ...Sort) SqlSchemaOption {
+	return func(schema *SqlSchema) {
+		schema.defaultSort = sort
+	}
+}
+
+// WithSqlTiebreaker sets a unique field appended to every sort, making pagination deterministic.
+// It follows the direction of the last sort and is skipped when grouping by other fields.
+func WithSqlTiebreaker(field string) SqlSchemaOption {
+	return func(schema *SqlSchema) {
+		schema.tiebreaker = field
+	}
+}
+
+// validateSort validates the default sort and tiebreaker of the schema.
+func (s *SqlSchema) validateSort() error {
+	fields := []string{}
+
+	for _, sort := range s.defaultSort {
+		if !sliceContainsString(validNulls, sort.Nulls) {
+			return &FieldError{Field: sort.Field, Err: ErrInvalidSort}
+		}
+
+		fields = append(fields, sort.Field)
+	}
+
+	if s.tiebreaker != "" {
+		fields = append(fields, s.tiebreaker)
+	}
+
+	for _, field := range fields {
+		translation, ok := s.translations[field]
+		if !ok || !translation.Capabilities.Has(SqlSortable) {
+			return &FieldError{Field: field, Err: ErrInvalidSort}
+		}
+	}
+
+	return nil
+}
+
+// sortsOf returns the sorts of a query, falling back to the default sort and ending with the tiebreaker.
+func (s *SqlSchema) sortsOf(query Query) []Sort {
+	if len(s.defaultSort) == 0 && s.tiebreaker == "" {
+		return query.Sort
+	}
+
+	sorts := append([]Sort{}, query.Sort...)
+
+	if len(sorts) == 0 {
+		for _, sort := range s.defaultSort {
+			if len(query.Group) == 0 || sliceContainsString(query.Group, sort.Field) {
+				sorts = append(sorts, sort)
+			}
+		}
+	}
+
+	if s.tiebreaker == "" || (len(query.Group) > 0 && !sliceContainsString(query.Group, s.tiebreaker)) {
+		return sorts
+	}
+
+	reverse := false
+
+	for _, sort := range sorts {
+		if sort.Field == s.tiebreaker {
+			return sorts
+		}
+
+		reverse = sort.Reverse
+	}
+
+	return append(sorts, Sort{Field: s.tiebreaker, Reverse: reverse})
+}
@@ -0,0 +1,77 @@
+package talkback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlDefaultSortAndTiebreaker(t *testing.T) {
+	schema := MustCompileSqlSchema(SqlTranslations{
+		"id":         SqlFieldTranslation{},
+		"created_at": SqlFieldTranslation{},
+		"status":     SqlFieldTranslation{},
+	}, nil,
+		WithSqlDefaultSort(Sort{Field: "created_at", Reverse: true}),
+		WithSqlTiebreaker("id"),
+	)
+
+	type scenarioT struct {
+		name      string
+		query     Query
+		statement string
+	}
+
+	scenarios := []scenarioT{
+		{
+			name:      "default sort",
+			query:     Query{},
+			statement: `"created_at" DESC, "id" DESC`,
+		},
+		{
+			name:      "client sort",
+			query:     Query{Sort: []Sort{{Field: "status"}}},
+			statement: `"status" ASC, "id" ASC`,
+		},
+		{
+			name:      "sorted by tiebreaker",
+			query:     Query{Sort: []Sort{{Field: "id", Reverse: true}, {Field: "status"}}},
+			statement: `"id" DESC, "status" ASC`,
+		},
+		{
+			name:      "grouped",
+			query:     Query{Group: []string{"status"}},
+			statement: ``,
+		},
+		{
+			name:      "grouped by sort fields",
+			query:     Query{Group: []string{"created_at", "id"}},
+			statement: `"created_at" DESC, "id" DESC`,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			statement, err := schema.ToSqlOrderBy(scenario.query)
+
+			assert.NoError(t, err, "error should be nil")
+			assert.Equal(t, scenario.statement, statement, "statement should be equal")
+		})
+	}
+}
+
+func TestSqlDefaultSortInvalid(t *testing.T) {
+	translations := SqlTranslations{
+		"id":   SqlFieldTranslation{},
+		"name": SqlFieldTranslation{Capabilities: SqlFilterable},
+	}
+
+	_, err := CompileSqlSchema(translations, nil, WithSqlDefaultSort(Sort{Field: "missing"}))
+	assert.Equal(t, &FieldError{Field: "missing", Err: ErrInvalidSort}, err, "err should be equal")
+
+	_, err = CompileSqlSchema(translations, nil, WithSqlDefaultSort(Sort{Field: "id", Nulls: "last"}))
+	assert.Equal(t, &FieldError{Field: "id", Err: ErrInvalidSort}, err, "err should be equal")
+
+	_, err = CompileSqlSchema(translations, nil, WithSqlTiebreaker("name"))
+	assert.Equal(t, &FieldError{Field: "name", Err: ErrInvalidSort}, err, "err should be equal")
+}
@@ -1,16 +1,59 @@
 package talkback
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
 	"strconv"
-	"strings"
 	"time"
 )
 
 // SqlFieldTranslation is a translation from a field name to a SQL field.
 type SqlFieldTranslation struct {
-	Column        string
+	Column        string // Column is a plain column name, optionally qualified, quoted unless Raw.
 	Alias         string
+	Raw           bool // Raw marks Column as a raw SQL expression, neither validated nor quoted.
 	TypeConverter func(value string) (interface{}, error)
+	Ops           []string      // Ops is a list of allowed operations, defaults depend on TypeConverter and Text.
+	Text          bool          // Text marks converted values as text, allowing text operations by default.
+	Capabilities  SqlCapability // Capabilities is what the field can be used for, defaults to all.
+	Scope         *SqlScope     // Scope makes the field a mandatory condition hidden from clients.
+	Kind          SqlKind       // Kind is the kind of value stored in the column, defaults to scalar.
+
+	// JSONKeys maps subpaths allowed on a SqlKindJSON field to their type converters, nil keeps values as text.
+	JSONKeys map[string]func(value string) (interface{}, error)
+	// JSONKeyPattern matches other subpaths allowed on a SqlKindJSON field, converted by TypeConverter.
+	JSONKeyPattern *regexp.Regexp
+
+	Enum []SqlEnumValue // Enum maps labels to stored values, sets TypeConverter and orders by position.
+
+	Expr      string        // Expr is a SQL expression used instead of Column, with ? placeholders for ExprArgs.
+	ExprArgs  []interface{} // ExprArgs are the arguments bound to the placeholders of Expr.
+	Aggregate bool          // Aggregate makes conditions on the field go to HAVING instead of WHERE.
+	UseAlias  bool          // UseAlias references the field by its alias in ORDER BY and HAVING.
+
+	Nulls     string // Nulls is the default placement of nulls when sorting, NullsFirst or NullsLast.
+	Collation string // Collation is the collation used when sorting, e.g. "und-x-icu" or "utf8mb4_unicode_ci".
+
+	Joins []string // Joins is a list of joins needed by the field, see WithSqlJoins.
+}
+
+// SqlCapability is a set of flags describing how a field can be used.
+type SqlCapability int
+
+const (
+	SqlFilterable SqlCapability = 1 << iota // WHERE
+	SqlSortable                             // ORDER BY
+	SqlGroupable                            // GROUP BY
+	SqlSelectable                           // SELECT
+
+	SqlAllCapabilities = SqlFilterable | SqlSortable | SqlGroupable | SqlSelectable
+)
+
+// Has returns true if all capabilities in other are set.
+func (c SqlCapability) Has(other SqlCapability) bool {
+	return c&other == other
 }
 
 // SqlTranslations is a map of field names to SQL translations.
@@ -18,70 +61,146 @@ type SqlTranslations map[string]SqlFieldTranslation
 
 // ToSqlWhere converts a Query to a SQL WHERE statement.
 func ToSqlWhere(query Query, translations SqlTranslations) (string, []interface{}, error) {
-	statements := []string{}
-	args := []interface{}{}
+	return ToSqlWhereContext(context.Background(), query, translations)
+}
 
-	translations = sanitizeSqlTranslation(translations)
+// ToSqlWhereContext converts a Query to a SQL WHERE statement, including scope conditions computed from ctx.
+func ToSqlWhereContext(ctx context.Context, query Query, translations SqlTranslations) (string, []interface{}, error) {
+	schema, err := compileUsedSqlSchema(query, translations, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return schema.ToSqlWhereContext(ctx, query)
+}
+
+// compileUsedSqlSchema compiles a SqlSchema of the translations used by a query and the
+// scoped ones, for functions compiling translations on each call.
+func compileUsedSqlSchema(query Query, translations SqlTranslations, preloadable SqlPreloadable) (*SqlSchema, error) {
+	return compileSqlSchema(translations, func(field string, translation SqlFieldTranslation) bool {
+		return translation.Scope != nil || queryUsesField(query, field)
+	}, preloadable)
+}
 
+// queryUsesField returns true if a query uses field, or a dotted subfield of it in conditions.
+func queryUsesField(query Query, field string) bool {
 	for _, cond := range query.Conditions {
-		translation, ok := translations[cond.Field]
-		if !ok {
-			return "", nil, ErrInvalidField
+		if cond.Field == field || (len(cond.Field) > len(field) && cond.Field[len(field)] == '.' && cond.Field[:len(field)] == field) {
+			return true
 		}
+	}
 
-		statement, arg, err := conditionToSql(translation, cond)
-		if err != nil {
-			return "", nil, err
+	for _, sort := range query.Sort {
+		if sort.Field == field {
+			return true
 		}
+	}
 
-		statements = append(statements, statement)
+	return sliceContainsString(query.Group, field) || sliceContainsString(query.Accumulator, field)
+}
 
-		if arg != nil {
-			args = append(args, arg)
-		}
+// sanitizeSqlFieldTranslation fills the defaults of a SqlFieldTranslation.
+func sanitizeSqlFieldTranslation(field string, translation SqlFieldTranslation) SqlFieldTranslation {
+	if translation.Expr != "" {
+		translation.Column = sqlExprToken(field)
+	}
+
+	if translation.Column == "" {
+		translation.Column = field
+	}
+
+	if translation.Alias == "" {
+		translation.Alias = field
+	}
+
+	if translation.Enum != nil && translation.TypeConverter == nil {
+		translation.TypeConverter = enumConverter(translation.Enum)
+	}
+
+	if translation.Ops == nil {
+		translation.Ops = sqlDefaultOpsOf(translation.Text, translation.TypeConverter)
+	}
+
+	if translation.Capabilities == 0 {
+		translation.Capabilities = SqlAllCapabilities
+	}
+
+	return translation
+}
+
+// sqlTextOps is a list of operations that only make sense on text values.
+var sqlTextOps = []string{
+	OpContain,
+	OpNcontain,
+	OpContains,
+	OpNcontains,
+}
+
+// sqlOptInOps is a list of operations only allowed when listed in Ops explicitly.
+var sqlOptInOps = append(append([]string{}, regexOps...), arrayOps...)
+
+// sqlDefaultOps and sqlDefaultTextOps are the operations allowed on translations without explicit Ops.
+var (
+	sqlDefaultOps     = defaultSqlOps(false)
+	sqlDefaultTextOps = defaultSqlOps(true)
+)
+
+// sqlDefaultOpsOf returns the default operations of values converted by converter,
+// text operations being allowed when values are kept as text or marked as text.
+func sqlDefaultOpsOf(text bool, converter func(value string) (interface{}, error)) []string {
+	if text || isSqlTextConverter(converter) {
+		return sqlDefaultTextOps
 	}
 
-	return strings.Join(statements, " AND "), args, nil
+	return sqlDefaultOps
 }
 
-// sanitizeSqlTranslation sanitizes a SqlTranslations map.
-func sanitizeSqlTranslation(translations SqlTranslations) SqlTranslations {
-	result := SqlTranslations{}
+// defaultSqlOps returns the operations allowed by default on text or non-text values.
+func defaultSqlOps(text bool) []string {
+	ops := []string{}
 
-	for field, translation := range translations {
-		if translation.Column == "" {
-			translation.Column = field
+	for _, op := range validOps {
+		if sliceContainsString(sqlOptInOps, op) {
+			continue
 		}
 
-		if translation.Alias == "" {
-			translation.Alias = field
+		if sliceContainsString(sqlTextOps, op) && !text {
+			continue
 		}
 
-		result[field] = translation
+		ops = append(ops, op)
 	}
 
-	return result
+	return ops
 }
 
-// valueToSql converts a value to a SQL argument.
-func valueToSql(translation SqlFieldTranslation, value string) (interface{}, error) {
-	var result interface{} = value
+// isSqlTextConverter returns true if the converter keeps values as text:
+// no converter, SqlConvertString or a SqlChain of text steps.
+func isSqlTextConverter(converter func(value string) (interface{}, error)) bool {
+	if converter == nil {
+		return true
+	}
 
-	if translation.TypeConverter != nil {
-		var err error
+	pointer := reflect.ValueOf(converter).Pointer()
 
-		result, err = translation.TypeConverter(value)
-		if err != nil {
-			return nil, err
-		}
+	return pointer == sqlConvertStringPointer || pointer == sqlTextChainPointer
+}
+
+// sqlConvertStringPointer is the code pointer of SqlConvertString.
+var sqlConvertStringPointer = reflect.ValueOf(SqlConvertString).Pointer()
+
+// valueToSql converts a value to a SQL argument.
+func valueToSql(translation SqlFieldTranslation, value string) (interface{}, error) {
+	if translation.TypeConverter == nil {
+		return value, nil
 	}
 
-	return result, nil
+	return translation.TypeConverter(value)
 }
 
 // sliceValuesToSql converts a slice of values to a slice of SQL arguments.
 func sliceValuesToSql(translation SqlFieldTranslation, values []string) ([]interface{}, error) {
-	var result []interface{}
+	result := make([]interface{}, 0, len(values))
 
 	for _, value := range values {
 		r, err := valueToSql(translation, value)
@@ -95,52 +214,111 @@ func sliceValuesToSql(translation SqlFieldTranslation, values []string) ([]inter
 	return result, nil
 }
 
-// conditionToSql converts a Condition to a SQL statement and argument.
-func conditionToSql(translation SqlFieldTranslation, cond Condition) (string, interface{}, error) {
+// conditionToSql converts a Condition to a SQL statement and arguments in dialect.
+func conditionToSql(dialect SqlDialect, translation SqlFieldTranslation, cond Condition) (string, []interface{}, error) {
+	if len(cond.Values) == 0 {
+		err := fmt.Errorf("%w: %s takes at least 1 value", ErrInvalidValue, cond.Op)
+		return "", nil, &FieldError{Field: cond.Field, Op: cond.Op, Err: err}
+	}
+
+	if !sliceContainsString(multiValueOps, cond.Op) && len(cond.Values) > 1 {
+		err := fmt.Errorf("%w: %s takes 1 value, got %d", ErrInvalidValue, cond.Op, len(cond.Values))
+		return "", nil, &FieldError{Field: cond.Field, Op: cond.Op, Err: err}
+	}
+
+	if sliceContainsString(nullOps, cond.Op) {
+		return nullConditionToSql(translation, cond)
+	}
+
+	if sliceContainsString(arrayOps, cond.Op) {
+		return arrayConditionToSql(dialect, translation, cond)
+	}
+
 	sliceValue, err := sliceValuesToSql(translation, cond.Values)
 	if err != nil {
-		return "", nil, err
+		return "", nil, &FieldError{Field: cond.Field, Op: cond.Op, Err: err}
+	}
+
+	if sliceContainsString(rangeOps, cond.Op) && len(sliceValue) != 2 {
+		err := fmt.Errorf("%w: %s takes 2 values, got %d", ErrInvalidValue, cond.Op, len(sliceValue))
+		return "", nil, &FieldError{Field: cond.Field, Op: cond.Op, Err: err}
 	}
 
-	firstValue := sliceValue[0]
-	likeValue := "%" + cond.Values[0] + "%"
+	if ranges, ok := timeRanges(sliceValue); ok {
+		return timeRangeConditionToSql(translation.Column, cond, ranges)
+	}
+
+	// Single value operations use sliceValue as their only argument.
 	column := translation.Column
 
 	switch cond.Op {
-	case OpIsNull:
-		return column + " IS NULL", nil, nil
 	case OpEq:
-		return column + " = ?", firstValue, nil
+		return column + " = ?", sliceValue, nil
 	case OpNe:
-		return column + " != ?", firstValue, nil
+		return column + " != ?", sliceValue, nil
 	case OpGt:
-		return column + " > ?", firstValue, nil
+		return column + " > ?", sliceValue, nil
 	case OpGte:
-		return column + " >= ?", firstValue, nil
+		return column + " >= ?", sliceValue, nil
 	case OpLt:
-		return column + " < ?", firstValue, nil
+		return column + " < ?", sliceValue, nil
 	case OpLte:
-		return column + " <= ?", firstValue, nil
+		return column + " <= ?", sliceValue, nil
 	case OpContain:
-		return castAsText(column) + " ILIKE ?", likeValue, nil
+		return dialect.like(column, true, false), likeArgs(cond.Values[0]), nil
 	case OpNcontain:
-		return castAsText(column) + " NOT ILIKE ?", likeValue, nil
+		return dialect.like(column, true, true), likeArgs(cond.Values[0]), nil
 	case OpContains:
-		return castAsText(column) + " LIKE ?", likeValue, nil
+		return dialect.like(column, false, false), likeArgs(cond.Values[0]), nil
 	case OpNcontains:
-		return castAsText(column) + " NOT LIKE ?", likeValue, nil
+		return dialect.like(column, false, true), likeArgs(cond.Values[0]), nil
+	case OpRegex, OpNregex, OpIregex, OpNiregex:
+		statement, arg := dialect.regex(column, cond.Op, cond.Values[0])
+		return statement, []interface{}{arg}, nil
 	case OpIn:
-		return translation.Column + " IN (?)", sliceValue, nil
+		return translation.Column + " IN (?)", []interface{}{sliceValue}, nil
 	case OpNin:
-		return translation.Column + " NOT IN (?)", sliceValue, nil
+		return translation.Column + " NOT IN (?)", []interface{}{sliceValue}, nil
+	case OpBetween, OpNbetween:
+		if cond.Op == OpNbetween {
+			return column + " NOT BETWEEN ? AND ?", sliceValue, nil
+		}
+
+		return column + " BETWEEN ? AND ?", sliceValue, nil
 	default:
 		return "", nil, ErrInvalidOp
 	}
 }
 
-// castAsText casts a field as text.
-func castAsText(field string) string {
-	return "CAST(" + field + " AS TEXT)"
+// likeArgs returns the argument of a LIKE matching value anywhere.
+func likeArgs(value string) []interface{} {
+	return []interface{}{"%" + value + "%"}
+}
+
+// nullConditionToSql converts an isnull or notnull Condition to a SQL statement.
+// The value is a boolean negating the operation when false, an empty value means true.
+func nullConditionToSql(translation SqlFieldTranslation, cond Condition) (string, []interface{}, error) {
+	isNull := true
+
+	if cond.Values[0] != "" {
+		var err error
+
+		isNull, err = strconv.ParseBool(cond.Values[0])
+		if err != nil {
+			err := fmt.Errorf("%w: %q is not a boolean", ErrInvalidValue, cond.Values[0])
+			return "", nil, &FieldError{Field: cond.Field, Op: cond.Op, Err: err}
+		}
+	}
+
+	if cond.Op == OpNotNull {
+		isNull = !isNull
+	}
+
+	if isNull {
+		return translation.Column + " IS NULL", nil, nil
+	}
+
+	return translation.Column + " IS NOT NULL", nil, nil
 }
 
 // SqlConvertString is a TypeConverter that converts a string to a string.
@@ -185,100 +363,62 @@ func SqlConvertISO8601(value string) (interface{}, error) {
 
 // ToSqlSelect converts a Query to a SQL SELECT statement.
 func ToSqlSelect(query Query, translations SqlTranslations) (string, error) {
-	fields, err := ToSqlSelectSlice(query, translations)
+	schema, err := compileUsedSqlSchema(query, translations, nil)
 	if err != nil {
 		return "", err
 	}
 
-	return strings.Join(fields, ", "), nil
+	return schema.ToSqlSelect(query)
 }
 
 // ToSqlSelectSlice converts a Query to a slice of SQL SELECT statements.
 func ToSqlSelectSlice(query Query, translations SqlTranslations) ([]string, error) {
-	fields := []string{}
-	qSelects := query.Group
-	qSelects = append(qSelects, query.Accumulator...)
-
-	translations = sanitizeSqlTranslation(translations)
-
-	for _, field := range qSelects {
-		translation, ok := translations[field]
-		if !ok {
-			return nil, ErrInvalidField
-		}
-
-		col := translation.Column
-		if translation.Alias != translation.Column {
-			col = col + " AS " + translation.Alias
-		}
-
-		fields = append(fields, col)
+	schema, err := compileUsedSqlSchema(query, translations, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	return fields, nil
+	return schema.ToSqlSelectSlice(query)
 }
 
 // ToSqlGroup converts a Query to a SQL GROUP BY statement.
 func ToSqlGroup(query Query, translations SqlTranslations) (string, error) {
-	fields, err := ToSqlGroupSlice(query, translations)
+	schema, err := compileUsedSqlSchema(query, translations, nil)
 	if err != nil {
 		return "", err
 	}
 
-	return strings.Join(fields, ", "), nil
+	return schema.ToSqlGroup(query)
 }
 
 // ToSqlGroupSlice converts a Query to a slice of SQL GROUP BY statements.
 func ToSqlGroupSlice(query Query, translations SqlTranslations) ([]string, error) {
-	fields := []string{}
-
-	translations = sanitizeSqlTranslation(translations)
-
-	for _, field := range query.Group {
-		translation, ok := translations[field]
-		if !ok {
-			return nil, ErrInvalidField
-		}
-
-		fields = append(fields, translation.Column)
+	schema, err := compileUsedSqlSchema(query, translations, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	return fields, nil
+	return schema.ToSqlGroupSlice(query)
 }
 
 // ToSqlOrderBy converts a Query to a SQL ORDER BY statement.
 func ToSqlOrderBy(query Query, translations SqlTranslations) (string, error) {
-	fields, err := ToSqlOrderBySlice(query, translations)
+	schema, err := compileUsedSqlSchema(query, translations, nil)
 	if err != nil {
 		return "", err
 	}
 
-	return strings.Join(fields, ", "), nil
+	return schema.ToSqlOrderBy(query)
 }
 
 // ToSqlOrderBySlice converts a Query to a slice of SQL ORDER BY statements.
 func ToSqlOrderBySlice(query Query, translations SqlTranslations) ([]string, error) {
-	fields := []string{}
-
-	translations = sanitizeSqlTranslation(translations)
-
-	for _, field := range query.Sort {
-		translation, ok := translations[field.Field]
-		if !ok {
-			return nil, ErrInvalidField
-		}
-
-		col := translation.Column
-		if field.Reverse {
-			col = col + " DESC"
-		} else {
-			col = col + " ASC"
-		}
-
-		fields = append(fields, col)
+	schema, err := compileUsedSqlSchema(query, translations, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	return fields, nil
+	return schema.ToSqlOrderBySlice(query)
 }
 
 // ToSqlLimit converts a Query to a SQL LIMIT statement.
@@ -296,118 +436,59 @@ type SqlPreloadable map[string]string
 
 // ToSqlPreload converts a Query to a SQL preload statement.
 func ToSqlPreload(query Query, preloadable SqlPreloadable) ([]string, error) {
-	preloads := []string{}
-
-	for _, preload := range query.With {
-		model, ok := preloadable[preload]
-		if !ok {
-			return nil, ErrInvalidPreload
-		}
-
-		preloads = append(preloads, model)
+	schema, err := CompileSqlSchema(nil, preloadable)
+	if err != nil {
+		return nil, err
 	}
 
-	return preloads, nil
+	return schema.ToSqlPreload(query)
 }
 
+// ToSql converts a Query to a SQL SELECT query on table.
 func ToSql(table string, query Query, translations SqlTranslations) (string, []interface{}, error) {
-	cselect, err := ToSqlSelect(query, translations)
-	if err != nil {
-		return "", nil, err
-	}
-
-	cwhere, cwhereargs, err := ToSqlWhere(query, translations)
-	if err != nil {
-		return "", nil, err
-	}
-
-	cgroup, err := ToSqlGroup(query, translations)
-	if err != nil {
-		return "", nil, err
-	}
-
-	corder, err := ToSqlOrderBy(query, translations)
-	if err != nil {
-		return "", nil, err
-	}
-
-	climit, err := ToSqlLimit(query)
-	if err != nil {
-		return "", nil, err
-	}
+	return ToSqlContext(context.Background(), table, query, translations)
+}
 
-	coffset, err := ToSqlOffset(query)
+// ToSqlContext converts a Query to a SQL SELECT query on table, including scope conditions computed from ctx.
+func ToSqlContext(ctx context.Context, table string, query Query, translations SqlTranslations) (string, []interface{}, error) {
+	schema, err := compileUsedSqlSchema(query, translations, nil)
 	if err != nil {
 		return "", nil, err
 	}
 
-	sql := "SELECT " + cselect + " FROM " + table +
-		" WHERE " + cwhere +
-		" GROUP BY " + cgroup +
-		" ORDER BY " + corder +
-		" LIMIT " + strconv.Itoa(climit) +
-		" OFFSET " + strconv.Itoa(coffset)
-
-	return sql, cwhereargs, nil
+	return schema.ToSqlContext(ctx, table, query)
 }
 
 // SqlPlan is a plan for executing a query.
 type SqlPlan struct {
-	Select    string
-	Where     string
-	WhereArgs []interface{}
-	Group     string
-	Order     string
-	Limit     int
-	Offset    int
-	Preload   []string
+	Select     string
+	SelectArgs []interface{}
+	Joins      string
+	Where      string
+	WhereArgs  []interface{}
+	Group      string
+	GroupArgs  []interface{}
+	Having     string
+	HavingArgs []interface{}
+	Order      string
+	OrderArgs  []interface{}
+	Limit      int
+	Offset     int
+	Preload    []string
+	Preloads   []SqlPreloadPlan // Preloads are the plans of the preloads in Preload, in the same order.
 }
 
 // ToSqlPlan converts a Query to a SqlPlan.
 func ToSqlPlan(query Query, translations SqlTranslations, preloadable SqlPreloadable) (SqlPlan, error) {
-	cselect, err := ToSqlSelect(query, translations)
-	if err != nil {
-		return SqlPlan{}, err
-	}
-
-	cwhere, cwhereargs, err := ToSqlWhere(query, translations)
-	if err != nil {
-		return SqlPlan{}, err
-	}
-
-	cgroup, err := ToSqlGroup(query, translations)
-	if err != nil {
-		return SqlPlan{}, err
-	}
-
-	corder, err := ToSqlOrderBy(query, translations)
-	if err != nil {
-		return SqlPlan{}, err
-	}
-
-	climit, err := ToSqlLimit(query)
-	if err != nil {
-		return SqlPlan{}, err
-	}
-
-	coffset, err := ToSqlOffset(query)
-	if err != nil {
-		return SqlPlan{}, err
-	}
+	return ToSqlPlanContext(context.Background(), query, translations, preloadable)
+}
 
-	cpreload, err := ToSqlPreload(query, preloadable)
+// ToSqlPlanContext converts a Query to a SqlPlan, including scope conditions computed from ctx.
+func ToSqlPlanContext(ctx context.Context, query Query, translations SqlTranslations, preloadable SqlPreloadable) (SqlPlan, error) {
+	schema, err := compileUsedSqlSchema(query, translations, preloadable)
 	if err != nil {
 		return SqlPlan{}, err
 	}
 
-	return SqlPlan{
-		Select:    cselect,
-		Where:     cwhere,
-		WhereArgs: cwhereargs,
-		Group:     cgroup,
-		Order:     corder,
-		Limit:     climit,
-		Offset:    coffset,
-		Preload:   cpreload,
-	}, nil
+	return schema.ToSqlPlanContext(ctx, query)
 }
@@ -1,6 +1,7 @@
 package talkback
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"testing"
@@ -64,7 +65,7 @@ func TestToSqlWhere(t *testing.T) {
 					Column: "field3",
 				},
 			},
-			statement: "field1 = ? AND field2 != ? AND field3 IS NULL",
+			statement: `"field1" = ? AND "field2" != ? AND "field3" IS NULL`,
 			args:      []interface{}{"value1", parsedSampleTime},
 			err:       nil,
 		},
@@ -95,7 +96,7 @@ func TestToSqlWhere(t *testing.T) {
 					TypeConverter: SqlConvertFloat,
 				},
 			},
-			statement: "field1 > ? AND field2 < ? AND field3 >= ? AND field4 <= ?",
+			statement: `"field1" > ? AND "field2" < ? AND "field3" >= ? AND "field4" <= ?`,
 			args: []interface{}{
 				int(12),
 				parsedSampleDate,
@@ -127,7 +128,7 @@ func TestToSqlWhere(t *testing.T) {
 					Column: "field4",
 				},
 			},
-			statement: "CAST(field1 AS TEXT) ILIKE ? AND CAST(field2 AS TEXT) NOT ILIKE ? AND CAST(field3 AS TEXT) LIKE ? AND CAST(field4 AS TEXT) NOT LIKE ?",
+			statement: `CAST("field1" AS TEXT) ILIKE ? AND CAST("field2" AS TEXT) NOT ILIKE ? AND CAST("field3" AS TEXT) LIKE ? AND CAST("field4" AS TEXT) NOT LIKE ?`,
 			args: []interface{}{
 				"%" + "value1" + "%",
 				"%" + "value2" + "%",
@@ -153,13 +154,63 @@ func TestToSqlWhere(t *testing.T) {
 					TypeConverter: SqlConvertISO8601,
 				},
 			},
-			statement: "field1 IN (?) AND field2 NOT IN (?)",
+			statement: `"field1" IN (?) AND "field2" NOT IN (?)`,
 			args: []interface{}{
 				[]interface{}{parsedSampleDateTime, parsedSampleDateTime},
 				[]interface{}{parsedSampleISO8601, parsedSampleISO8601},
 			},
 			err: nil,
 		},
+		{
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "isnull", []string{"false"}},
+					{"field2", "notnull", []string{""}},
+					{"field3", "notnull", []string{"false"}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					Column:        "field1",
+					TypeConverter: SqlConvertDate,
+				},
+				"field2": SqlFieldTranslation{
+					Column: "field2",
+				},
+				"field3": SqlFieldTranslation{
+					Column: "field3",
+				},
+			},
+			statement: `"field1" IS NOT NULL AND "field2" IS NOT NULL AND "field3" IS NULL`,
+			args:      []interface{}{},
+			err:       nil,
+		},
+		{
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "between", []string{"1", "10"}},
+					{"field2", "nbetween", []string{sampleDate, sampleDate}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					Column:        "field1",
+					TypeConverter: SqlConvertInt,
+				},
+				"field2": SqlFieldTranslation{
+					Column:        "field2",
+					TypeConverter: SqlConvertDate,
+				},
+			},
+			statement: `"field1" BETWEEN ? AND ? AND "field2" NOT BETWEEN ? AND ?`,
+			args: []interface{}{
+				int(1),
+				int(10),
+				parsedSampleDate,
+				parsedSampleDate,
+			},
+			err: nil,
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -173,6 +224,192 @@ func TestToSqlWhere(t *testing.T) {
 	}
 }
 
+func TestToSqlWhereOps(t *testing.T) {
+	type scenarioT struct {
+		name         string
+		query        Query
+		translations SqlTranslations
+		err          error
+	}
+
+	scenarios := []scenarioT{
+		{
+			name: "text op on untyped field",
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "contain", []string{"value1"}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{},
+			},
+			err: nil,
+		},
+		{
+			name: "text op on typed field",
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "contain", []string{"12"}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					TypeConverter: SqlConvertInt,
+				},
+			},
+			err: &FieldError{Field: "field1", Op: "contain", Err: ErrDisallowedOp},
+		},
+		{
+			name: "text op on text converter",
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "contain", []string{" Value1 "}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					TypeConverter: SqlChain(SqlTrim, SqlLower),
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "text op on string converter",
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "contain", []string{"value1"}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					TypeConverter: SqlConvertString,
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "text op on non text chain",
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "contain", []string{"1"}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					TypeConverter: SqlChain(SqlTrim, SqlConvertWith(SqlConvertInt)),
+				},
+			},
+			err: &FieldError{Field: "field1", Op: "contain", Err: ErrDisallowedOp},
+		},
+		{
+			name: "text op on marked converter",
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "contain", []string{"value1"}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					TypeConverter: SqlChain(SqlTrim, SqlConvertWith(SqlConvertString)),
+					Text:          true,
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "op not in explicit ops",
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "ne", []string{"value1"}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					Ops: []string{OpEq, OpIn},
+				},
+			},
+			err: &FieldError{Field: "field1", Op: "ne", Err: ErrDisallowedOp},
+		},
+		{
+			name: "eq with two values",
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "eq", []string{"1", "2"}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{},
+			},
+			err: &FieldError{Field: "field1", Op: "eq", Err: fmt.Errorf("%w: eq takes 1 value, got 2", ErrInvalidValue)},
+		},
+		{
+			name: "isnull with non boolean value",
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "isnull", []string{"yes"}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{},
+			},
+			err: &FieldError{Field: "field1", Op: "isnull", Err: fmt.Errorf("%w: \"yes\" is not a boolean", ErrInvalidValue)},
+		},
+		{
+			name: "between with one value",
+			query: Query{
+				Conditions: []Condition{
+					{"field1", "between", []string{"1"}},
+				},
+			},
+			translations: SqlTranslations{
+				"field1": SqlFieldTranslation{
+					TypeConverter: SqlConvertInt,
+				},
+			},
+			err: &FieldError{Field: "field1", Op: "between", Err: fmt.Errorf("%w: between takes 2 values, got 1", ErrInvalidValue)},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, _, err := ToSqlWhere(scenario.query, scenario.translations)
+
+			assert.Equal(t, scenario.err, err, "err should be equal")
+		})
+	}
+}
+
+func TestToSqlCapabilities(t *testing.T) {
+	translations := SqlTranslations{
+		"field1": SqlFieldTranslation{
+			Capabilities: SqlSelectable,
+		},
+		"field2": SqlFieldTranslation{
+			Capabilities: SqlFilterable | SqlSortable,
+		},
+	}
+
+	_, _, err := ToSqlWhere(Query{Conditions: []Condition{{"field1", "eq", []string{"value1"}}}}, translations)
+	assert.Equal(t, &FieldError{Field: "field1", Err: ErrNotFilterable}, err, "err should be equal")
+
+	_, err = ToSqlOrderBySlice(Query{Sort: []Sort{{Field: "field1", Reverse: false}}}, translations)
+	assert.Equal(t, &FieldError{Field: "field1", Err: ErrNotSortable}, err, "err should be equal")
+
+	_, err = ToSqlGroupSlice(Query{Group: []string{"field2"}}, translations)
+	assert.Equal(t, &FieldError{Field: "field2", Err: ErrNotGroupable}, err, "err should be equal")
+
+	_, err = ToSqlSelectSlice(Query{Accumulator: []string{"field2"}}, translations)
+	assert.Equal(t, &FieldError{Field: "field2", Err: ErrNotSelectable}, err, "err should be equal")
+
+	statement, err := ToSqlSelect(Query{Accumulator: []string{"field1"}}, translations)
+	assert.NoError(t, err, "error should be nil")
+	assert.Equal(t, `"field1"`, statement, "statement should be equal")
+
+	statement, err = ToSqlOrderBy(Query{Sort: []Sort{{Field: "field2", Reverse: true}}}, translations)
+	assert.NoError(t, err, "error should be nil")
+	assert.Equal(t, `"field2" DESC`, statement, "statement should be equal")
+}
+
 func TestToSqlSelect(t *testing.T) {
 	type scenarioT struct {
 		query        Query
@@ -190,7 +427,7 @@ func TestToSqlSelect(t *testing.T) {
 				"field1": SqlFieldTranslation{},
 				"field2": SqlFieldTranslation{},
 			},
-			statement: "field1, field2",
+			statement: `"field1", "field2"`,
 			err:       nil,
 		},
 		{
@@ -205,7 +442,7 @@ func TestToSqlSelect(t *testing.T) {
 					Column: "ex.field2",
 				},
 			},
-			statement: "ex.field1 AS field1, ex.field2 AS field2",
+			statement: `"ex"."field1" AS "field1", "ex"."field2" AS "field2"`,
 			err:       nil,
 		},
 		{
@@ -220,7 +457,7 @@ func TestToSqlSelect(t *testing.T) {
 					Alias: "alias2",
 				},
 			},
-			statement: "field1 AS alias1, field2 AS alias2",
+			statement: `"field1" AS "alias1", "field2" AS "alias2"`,
 			err:       nil,
 		},
 	}
@@ -252,7 +489,7 @@ func TestToSqlGroup(t *testing.T) {
 				"field1": SqlFieldTranslation{},
 				"field2": SqlFieldTranslation{},
 			},
-			statement: "field1, field2",
+			statement: `"field1", "field2"`,
 			err:       nil,
 		},
 		{
@@ -267,7 +504,7 @@ func TestToSqlGroup(t *testing.T) {
 					Column: "ex.field2",
 				},
 			},
-			statement: "ex.field1, ex.field2",
+			statement: `"ex"."field1", "ex"."field2"`,
 			err:       nil,
 		},
 	}
@@ -294,22 +531,22 @@ func TestToSqlOrderBy(t *testing.T) {
 		{
 			query: Query{
 				Sort: []Sort{
-					{"field1", false},
-					{"field2", true},
+					{Field: "field1", Reverse: false},
+					{Field: "field2", Reverse: true},
 				},
 			},
 			translations: SqlTranslations{
 				"field1": SqlFieldTranslation{},
 				"field2": SqlFieldTranslation{},
 			},
-			statement: "field1 ASC, field2 DESC",
+			statement: `"field1" ASC, "field2" DESC`,
 			err:       nil,
 		},
 		{
 			query: Query{
 				Sort: []Sort{
-					{"field1", false},
-					{"field2", true},
+					{Field: "field1", Reverse: false},
+					{Field: "field2", Reverse: true},
 				},
 			},
 			translations: SqlTranslations{
@@ -320,7 +557,7 @@ func TestToSqlOrderBy(t *testing.T) {
 					Column: "ex.field2",
 				},
 			},
-			statement: "ex.field1 ASC, ex.field2 DESC",
+			statement: `"ex"."field1" ASC, "ex"."field2" DESC`,
 			err:       nil,
 		},
 	}
@@ -453,8 +690,8 @@ func TestToSql(t *testing.T) {
 				},
 				Group: []string{"field1", "field2"},
 				Sort: []Sort{
-					{"field1", false},
-					{"field2", true},
+					{Field: "field1", Reverse: false},
+					{Field: "field2", Reverse: true},
 				},
 				Limit: 10,
 				Skip:  10,
@@ -467,7 +704,7 @@ func TestToSql(t *testing.T) {
 					Column: "ex.field2",
 				},
 			},
-			statement: "SELECT field1 AS alias1, ex.field2 AS field2 FROM somewhere WHERE field1 = ? AND ex.field2 != ? GROUP BY field1, ex.field2 ORDER BY field1 ASC, ex.field2 DESC LIMIT 10 OFFSET 10",
+			statement: `SELECT "field1" AS "alias1", "ex"."field2" AS "field2" FROM somewhere WHERE "field1" = ? AND "ex"."field2" != ? GROUP BY "field1", "ex"."field2" ORDER BY "field1" ASC, "ex"."field2" DESC LIMIT 10 OFFSET 10`,
 			args:      []interface{}{"value1", "value2"},
 			err:       nil,
 		},
@@ -0,0 +1,256 @@
+package talkback
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SqlTimeConverter converts absolute and relative times, such as now-7d or
+// startofmonth, to a time.Time. A relative expression is an anchor (now,
+// today, startofweek, startofmonth, startofyear) followed by any number of
+// offsets (+1d, -2h) and an optional rounding (/h). Units are s, m, h, d, w,
+// M and y.
+type SqlTimeConverter struct {
+	Layouts  []string         // Layouts is a list of accepted absolute layouts, defaults to ISO8601, datetime and date.
+	Location *time.Location   // Location is the time zone of relative times and layouts without offset, defaults to UTC.
+	Now      func() time.Time // Now returns the current time, defaults to time.Now.
+}
+
+// sqlTimeDefaultLayouts is a list of layouts accepted by default by SqlTimeConverter.
+var sqlTimeDefaultLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// sqlRelativeTimePattern matches a relative time expression.
+var sqlRelativeTimePattern = regexp.MustCompile(`^(now|today|startofweek|startofmonth|startofyear)((?:[+-]\d+[smhdwMy])*)(?:/([smhdwMy]))?$`)
+
+// sqlRelativeOffsetPattern matches a single offset of a relative time expression.
+var sqlRelativeOffsetPattern = regexp.MustCompile(`([+-]\d+)([smhdwMy])`)
+
+// SqlConvertRelativeTime is a TypeConverter that converts an absolute or relative time to a time.Time in UTC.
+func SqlConvertRelativeTime(value string) (interface{}, error) {
+	return SqlTimeConverter{}.Convert(value)
+}
+
+// Convert is a TypeConverter that converts an absolute or relative time to a time.Time.
+func (c SqlTimeConverter) Convert(value string) (interface{}, error) {
+	return c.parse(value)
+}
+
+// parse parses an absolute or relative time.
+func (c SqlTimeConverter) parse(value string) (time.Time, error) {
+	loc := c.location()
+
+	if match := sqlRelativeTimePattern.FindStringSubmatch(value); match != nil {
+		t := c.anchor(match[1])
+
+		for _, offset := range sqlRelativeOffsetPattern.FindAllStringSubmatch(match[2], -1) {
+			n, err := strconv.Atoi(offset[1])
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%w: %q is not a valid time", ErrInvalidValue, value)
+			}
+
+			t = addTimeUnit(t, n, offset[2])
+		}
+
+		if match[3] != "" {
+			t = truncateTimeUnit(t, match[3])
+		}
+
+		return t, nil
+	}
+
+	layouts := c.Layouts
+	if len(layouts) == 0 {
+		layouts = sqlTimeDefaultLayouts
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q is not a valid time", ErrInvalidValue, value)
+}
+
+// location returns the time zone of the converter.
+func (c SqlTimeConverter) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+
+	return c.Location
+}
+
+// anchor returns the time of a relative expression anchor.
+func (c SqlTimeConverter) anchor(name string) time.Time {
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+
+	t := now().In(c.location())
+
+	switch name {
+	case "today":
+		return truncateTimeUnit(t, "d")
+	case "startofweek":
+		return truncateTimeUnit(t, "w")
+	case "startofmonth":
+		return truncateTimeUnit(t, "M")
+	case "startofyear":
+		return truncateTimeUnit(t, "y")
+	default:
+		return t
+	}
+}
+
+// addTimeUnit adds n units to t, calendar units follow the time zone of t.
+func addTimeUnit(t time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "s":
+		return t.Add(time.Duration(n) * time.Second)
+	case "m":
+		return t.Add(time.Duration(n) * time.Minute)
+	case "h":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "d":
+		return t.AddDate(0, 0, n)
+	case "w":
+		return t.AddDate(0, 0, 7*n)
+	case "M":
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(n, 0, 0)
+	}
+}
+
+// truncateTimeUnit rounds t down to the start of its unit, weeks start on Monday.
+func truncateTimeUnit(t time.Time, unit string) time.Time {
+	y, mo, d := t.Date()
+	h, mi, s := t.Clock()
+	loc := t.Location()
+
+	switch unit {
+	case "s":
+		return time.Date(y, mo, d, h, mi, s, 0, loc)
+	case "m":
+		return time.Date(y, mo, d, h, mi, 0, 0, loc)
+	case "h":
+		return time.Date(y, mo, d, h, 0, 0, 0, loc)
+	case "d":
+		return time.Date(y, mo, d, 0, 0, 0, 0, loc)
+	case "w":
+		weekday := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, mo, d-weekday, 0, 0, 0, 0, loc)
+	case "M":
+		return time.Date(y, mo, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+	}
+}
+
+// SqlConvertDateIn returns a TypeConverter that converts a date in loc to a time.Time, nil loc means UTC.
+// TypeConverters do not see the request, so a client time zone is applied by passing it as loc.
+func SqlConvertDateIn(loc *time.Location) func(value string) (interface{}, error) {
+	loc = SqlTimeConverter{Location: loc}.location()
+
+	return func(value string) (interface{}, error) {
+		return time.ParseInLocation("2006-01-02", value, loc)
+	}
+}
+
+// SqlConvertDateTimeIn returns a TypeConverter that converts a datetime in loc to a time.Time, nil loc means UTC.
+func SqlConvertDateTimeIn(loc *time.Location) func(value string) (interface{}, error) {
+	loc = SqlTimeConverter{Location: loc}.location()
+
+	return func(value string) (interface{}, error) {
+		return time.ParseInLocation("2006-01-02 15:04:05", value, loc)
+	}
+}
+
+// SqlTimeRange is a half-open [From, To) range of time.
+type SqlTimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// SqlConvertDayIn returns a TypeConverter that converts a date in loc to the
+// SqlTimeRange of that whole day, for filtering a timestamp column by date.
+// Comparisons are expanded on the range, so eq matches [day, day+1). A nil loc means UTC.
+func SqlConvertDayIn(loc *time.Location) func(value string) (interface{}, error) {
+	loc = SqlTimeConverter{Location: loc}.location()
+
+	return func(value string) (interface{}, error) {
+		day, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		return SqlTimeRange{From: day, To: day.AddDate(0, 0, 1)}, nil
+	}
+}
+
+// timeRanges returns values as time ranges, if they all are.
+func timeRanges(values []interface{}) ([]SqlTimeRange, bool) {
+	ranges := []SqlTimeRange{}
+
+	for _, value := range values {
+		r, ok := value.(SqlTimeRange)
+		if !ok {
+			return nil, false
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges, true
+}
+
+// timeRangeConditionToSql converts a Condition on time ranges to a SQL statement and arguments.
+func timeRangeConditionToSql(column string, cond Condition, ranges []SqlTimeRange) (string, []interface{}, error) {
+	within := "(" + column + " >= ? AND " + column + " < ?)"
+	outside := "(" + column + " < ? OR " + column + " >= ?)"
+
+	switch cond.Op {
+	case OpEq:
+		return within, []interface{}{ranges[0].From, ranges[0].To}, nil
+	case OpNe:
+		return outside, []interface{}{ranges[0].From, ranges[0].To}, nil
+	case OpGt:
+		return column + " >= ?", []interface{}{ranges[0].To}, nil
+	case OpGte:
+		return column + " >= ?", []interface{}{ranges[0].From}, nil
+	case OpLt:
+		return column + " < ?", []interface{}{ranges[0].From}, nil
+	case OpLte:
+		return column + " < ?", []interface{}{ranges[0].To}, nil
+	case OpBetween:
+		return within, []interface{}{ranges[0].From, ranges[1].To}, nil
+	case OpNbetween:
+		return outside, []interface{}{ranges[0].From, ranges[1].To}, nil
+	case OpIn, OpNin:
+		statements := []string{}
+		args := []interface{}{}
+
+		for _, r := range ranges {
+			statements = append(statements, within)
+			args = append(args, r.From, r.To)
+		}
+
+		statement := "(" + strings.Join(statements, " OR ") + ")"
+		if cond.Op == OpNin {
+			statement = "NOT " + statement
+		}
+
+		return statement, args, nil
+	default:
+		return "", nil, &FieldError{Field: cond.Field, Op: cond.Op, Err: ErrDisallowedOp}
+	}
+}
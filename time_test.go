@@ -0,0 +1,149 @@
+package talkback
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlTimeConverter(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	converter := SqlTimeConverter{
+		Location: jakarta,
+		Now: func() time.Time {
+			return time.Date(2023, time.July, 12, 20, 30, 15, 0, time.UTC)
+		},
+	}
+
+	type scenarioT struct {
+		value string
+		out   time.Time
+	}
+
+	scenarios := []scenarioT{
+		{"now", time.Date(2023, time.July, 13, 3, 30, 15, 0, jakarta)},
+		{"now-7d", time.Date(2023, time.July, 6, 3, 30, 15, 0, jakarta)},
+		{"now-1h/h", time.Date(2023, time.July, 13, 2, 0, 0, 0, jakarta)},
+		{"now+1M-2d/d", time.Date(2023, time.August, 11, 0, 0, 0, 0, jakarta)},
+		{"today", time.Date(2023, time.July, 13, 0, 0, 0, 0, jakarta)},
+		{"startofweek", time.Date(2023, time.July, 10, 0, 0, 0, 0, jakarta)},
+		{"startofmonth-1M", time.Date(2023, time.June, 1, 0, 0, 0, 0, jakarta)},
+		{"startofyear+1y", time.Date(2024, time.January, 1, 0, 0, 0, 0, jakarta)},
+		{"2023-01-31", time.Date(2023, time.January, 31, 0, 0, 0, 0, jakarta)},
+		{"2023-01-31 10:00:00", time.Date(2023, time.January, 31, 10, 0, 0, 0, jakarta)},
+		{"2023-01-31T10:00:00Z", time.Date(2023, time.January, 31, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.value, func(t *testing.T) {
+			out, err := converter.Convert(scenario.value)
+
+			assert.NoError(t, err, "error should be nil")
+			assert.True(t, scenario.out.Equal(out.(time.Time)), "time should be equal, got %v", out)
+		})
+	}
+
+	for _, value := range []string{"yesterday", "now-7", "now/q", "2023-13-01"} {
+		t.Run(value, func(t *testing.T) {
+			_, err := converter.Convert(value)
+
+			assert.ErrorIs(t, err, ErrInvalidValue, "err should be invalid value")
+			assert.ErrorContains(t, err, value, "err should name the value")
+		})
+	}
+}
+
+func TestSqlConvertDateIn(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	date, err := SqlConvertDateIn(jakarta)("2023-07-01")
+	assert.NoError(t, err, "error should be nil")
+	assert.Equal(t, time.Date(2023, time.July, 1, 0, 0, 0, 0, jakarta), date, "date should be equal")
+	assert.Equal(t, time.Date(2023, time.June, 30, 17, 0, 0, 0, time.UTC), date.(time.Time).UTC(), "date should start 7 hours earlier in UTC")
+
+	datetime, err := SqlConvertDateTimeIn(jakarta)("2023-07-01 08:00:00")
+	assert.NoError(t, err, "error should be nil")
+	assert.Equal(t, time.Date(2023, time.July, 1, 1, 0, 0, 0, time.UTC), datetime.(time.Time).UTC(), "datetime should be equal")
+}
+
+func TestSqlConvertInNilLocation(t *testing.T) {
+	date, err := SqlConvertDateIn(nil)("2023-07-01")
+	assert.NoError(t, err, "error should be nil")
+	assert.Equal(t, time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC), date, "date should be in UTC")
+
+	datetime, err := SqlConvertDateTimeIn(nil)("2023-07-01 08:00:00")
+	assert.NoError(t, err, "error should be nil")
+	assert.Equal(t, time.Date(2023, time.July, 1, 8, 0, 0, 0, time.UTC), datetime, "datetime should be in UTC")
+
+	day, err := SqlConvertDayIn(nil)("2023-07-01")
+	assert.NoError(t, err, "error should be nil")
+	assert.Equal(t, SqlTimeRange{
+		From: time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2023, time.July, 2, 0, 0, 0, 0, time.UTC),
+	}, day, "day should be in UTC")
+}
+
+func TestSqlConvertDayIn(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	day1 := time.Date(2023, time.July, 1, 0, 0, 0, 0, jakarta)
+	day2 := time.Date(2023, time.July, 2, 0, 0, 0, 0, jakarta)
+	day3 := time.Date(2023, time.July, 3, 0, 0, 0, 0, jakarta)
+
+	translations := SqlTranslations{
+		"created": SqlFieldTranslation{
+			Column:        "created_at",
+			TypeConverter: SqlConvertDayIn(jakarta),
+		},
+	}
+
+	type scenarioT struct {
+		cond      Condition
+		statement string
+		args      []interface{}
+	}
+
+	scenarios := []scenarioT{
+		{
+			cond:      Condition{"created", "eq", []string{"2023-07-01"}},
+			statement: `("created_at" >= ? AND "created_at" < ?)`,
+			args:      []interface{}{day1, day2},
+		},
+		{
+			cond:      Condition{"created", "ne", []string{"2023-07-01"}},
+			statement: `("created_at" < ? OR "created_at" >= ?)`,
+			args:      []interface{}{day1, day2},
+		},
+		{
+			cond:      Condition{"created", "gt", []string{"2023-07-01"}},
+			statement: `"created_at" >= ?`,
+			args:      []interface{}{day2},
+		},
+		{
+			cond:      Condition{"created", "lte", []string{"2023-07-01"}},
+			statement: `"created_at" < ?`,
+			args:      []interface{}{day2},
+		},
+		{
+			cond:      Condition{"created", "between", []string{"2023-07-01", "2023-07-02"}},
+			statement: `("created_at" >= ? AND "created_at" < ?)`,
+			args:      []interface{}{day1, day3},
+		},
+		{
+			cond:      Condition{"created", "nin", []string{"2023-07-01", "2023-07-02"}},
+			statement: `NOT (("created_at" >= ? AND "created_at" < ?) OR ("created_at" >= ? AND "created_at" < ?))`,
+			args:      []interface{}{day1, day2, day2, day3},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.cond.Op, func(t *testing.T) {
+			statement, args, err := ToSqlWhere(Query{Conditions: []Condition{scenario.cond}}, translations)
+
+			assert.NoError(t, err, "error should be nil")
+			assert.Equal(t, scenario.statement, statement, "statement should be equal")
+			assert.Equal(t, scenario.args, args, "args should be equal")
+		})
+	}
+}
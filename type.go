@@ -2,6 +2,7 @@ package talkback
 
 const (
 	OpIsNull    = "isnull"    // IS NULL
+	OpNotNull   = "notnull"   // IS NOT NULL
 	OpEq        = "eq"        // EQUALS
 	OpNe        = "ne"        // NOT EQUALS
 	OpGt        = "gt"        // GREATER THAN
@@ -14,11 +15,27 @@ const (
 	OpNcontains = "ncontains" // NOT CONTAINS CASE SENSITIVE
 	OpIn        = "in"        // IN
 	OpNin       = "nin"       // NOT IN
+	OpBetween   = "between"   // BETWEEN
+	OpNbetween  = "nbetween"  // NOT BETWEEN
+	OpRegex     = "regex"     // MATCHES REGULAR EXPRESSION
+	OpNregex    = "nregex"    // NOT MATCHES REGULAR EXPRESSION
+	OpIregex    = "iregex"    // MATCHES REGULAR EXPRESSION CASE INSENSITIVE
+	OpNiregex   = "niregex"   // NOT MATCHES REGULAR EXPRESSION CASE INSENSITIVE
+	OpHas       = "has"       // ARRAY HAS ELEMENT
+	OpHasAll    = "hasall"    // ARRAY HAS ALL ELEMENTS
+	OpHasAny    = "hasany"    // ARRAY HAS ANY ELEMENT
+	OpLenEq     = "leneq"     // ARRAY LENGTH EQUALS
+	OpLenNe     = "lenne"     // ARRAY LENGTH NOT EQUALS
+	OpLenGt     = "lengt"     // ARRAY LENGTH GREATER THAN
+	OpLenLt     = "lenlt"     // ARRAY LENGTH LESS THAN
+	OpLenGte    = "lengte"    // ARRAY LENGTH GREATER THAN OR EQUALS
+	OpLenLte    = "lenlte"    // ARRAY LENGTH LESS THAN OR EQUALS
 )
 
 // validOps is a list of valid operations.
 var validOps = []string{
 	OpIsNull,
+	OpNotNull,
 	OpEq,
 	OpNe,
 	OpGt,
@@ -31,6 +48,64 @@ var validOps = []string{
 	OpNcontains,
 	OpIn,
 	OpNin,
+	OpBetween,
+	OpNbetween,
+	OpRegex,
+	OpNregex,
+	OpIregex,
+	OpNiregex,
+	OpHas,
+	OpHasAll,
+	OpHasAny,
+	OpLenEq,
+	OpLenNe,
+	OpLenGt,
+	OpLenLt,
+	OpLenGte,
+	OpLenLte,
+}
+
+// nullOps is a list of operations on NULL, taking a boolean value.
+var nullOps = []string{
+	OpIsNull,
+	OpNotNull,
+}
+
+// regexOps is a list of regular expression operations.
+var regexOps = []string{
+	OpRegex,
+	OpNregex,
+	OpIregex,
+	OpNiregex,
+}
+
+// arrayOps is a list of operations on array values.
+var arrayOps = []string{
+	OpHas,
+	OpHasAll,
+	OpHasAny,
+	OpLenEq,
+	OpLenNe,
+	OpLenGt,
+	OpLenLt,
+	OpLenGte,
+	OpLenLte,
+}
+
+// multiValueOps is a list of operations taking more than one value.
+var multiValueOps = []string{
+	OpIn,
+	OpNin,
+	OpBetween,
+	OpNbetween,
+	OpHasAll,
+	OpHasAny,
+}
+
+// rangeOps is a list of operations taking a lower and an upper bound.
+var rangeOps = []string{
+	OpBetween,
+	OpNbetween,
 }
 
 // Op is a string representing a valid operation.
@@ -47,10 +122,23 @@ func (c Condition) Valid() bool {
 		len(c.Values) > 0
 }
 
+const (
+	NullsFirst = "nullsfirst" // NULLS FIRST
+	NullsLast  = "nullslast"  // NULLS LAST
+)
+
+// validNulls is a list of valid null placements, empty means the default.
+var validNulls = []string{
+	"",
+	NullsFirst,
+	NullsLast,
+}
+
 // Query is a query to filter on.
 type Sort struct {
 	Field   string
 	Reverse bool
+	Nulls   string // Nulls is the placement of nulls, NullsFirst or NullsLast, empty for the field default.
 }
 
 // Query is a query to filter on.
@@ -62,4 +150,6 @@ type Query struct {
 	Sort        []Sort
 	Limit       int
 	Skip        int
+	Search      string           // Search is a free text to search for.
+	WithQuery   map[string]Query // WithQuery maps entries of With to the sub-queries of their preloads.
 }
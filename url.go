@@ -6,21 +6,53 @@ import (
 	"strings"
 )
 
+// Parser parses query strings into a Query.
+type Parser struct {
+	// ListSeparator splits each value of multi-value operations (in, nin,
+	// between, nbetween, hasall, hasany) into a list, empty disables
+	// splitting. A separator preceded by a backslash is kept literally, and
+	// a double backslash stands for a single one.
+	ListSeparator string
+}
+
+// DefaultParser is the Parser used by FromQueryString and FromURLValues.
+var DefaultParser = Parser{
+	ListSeparator: ",",
+}
+
 // FromQueryString returns a Query from a query string.
 func FromQueryString(qs string) (Query, error) {
+	return DefaultParser.FromQueryString(qs)
+}
+
+// FromURLValues returns a Query from URL values.
+func FromURLValues(params url.Values) (Query, error) {
+	return DefaultParser.FromURLValues(params)
+}
+
+// FromQueryString returns a Query from a query string.
+func (p Parser) FromQueryString(qs string) (Query, error) {
 	params, err := url.ParseQuery(qs)
 	if err != nil {
 		return Query{}, err
 	}
 
-	return FromURLValues(params)
+	return p.FromURLValues(params)
 }
 
-// FromQueryString returns a Query from a query string.
-func FromURLValues(params url.Values) (Query, error) {
+// FromURLValues returns a Query from URL values.
+// Parameters prefixed with the name of a preload in with make up its sub-query, such as
+// comments.limit=5 or comments.author_eq=me for with=comments, so a filter on a preloaded
+// relation applies to the preloaded rows rather than to the rows preloading them.
+func (p Parser) FromURLValues(params url.Values) (Query, error) {
 	query := Query{}
+	with := params["with"]
 
 	for key, values := range params {
+		if preloadParam(with, key) != "" {
+			continue
+		}
+
 		spliten := strings.Split(key, "_")
 
 		if len(spliten) < 2 {
@@ -30,21 +62,57 @@ func FromURLValues(params url.Values) (Query, error) {
 		field := strings.Join(spliten[:len(spliten)-1], "_")
 		op := spliten[len(spliten)-1]
 
-		cond := Condition{
-			Field:  field,
-			Op:     op,
-			Values: values,
+		if sliceContainsString(multiValueOps, op) && p.ListSeparator != "" {
+			values = p.splitList(values)
 		}
 
-		if cond.Valid() {
-			query.Conditions = append(query.Conditions, cond)
+		conds := []Condition{{Field: field, Op: op, Values: values}}
+
+		// Repeated keys of scalar operations become separate conditions.
+		if !sliceContainsString(multiValueOps, op) && len(values) > 1 {
+			conds = []Condition{}
+
+			for _, value := range values {
+				conds = append(conds, Condition{Field: field, Op: op, Values: []string{value}})
+			}
+		}
+
+		for _, cond := range conds {
+			if cond.Valid() {
+				query.Conditions = append(query.Conditions, cond)
+			}
 		}
 	}
 
-	if with, ok := params["with"]; ok {
+	if len(with) > 0 {
 		query.With = with
 	}
 
+	subParams := map[string]url.Values{}
+
+	for key, values := range params {
+		if preload := preloadParam(with, key); preload != "" {
+			if subParams[preload] == nil {
+				subParams[preload] = url.Values{}
+			}
+
+			subParams[preload][key[len(preload)+1:]] = values
+		}
+	}
+
+	for preload, sub := range subParams {
+		subQuery, err := p.FromURLValues(sub)
+		if err != nil {
+			return Query{}, err
+		}
+
+		if query.WithQuery == nil {
+			query.WithQuery = map[string]Query{}
+		}
+
+		query.WithQuery[preload] = subQuery
+	}
+
 	if group, ok := params["group"]; ok {
 		query.Group = group
 	}
@@ -57,19 +125,30 @@ func FromURLValues(params url.Values) (Query, error) {
 		for _, s := range sort {
 			field := s
 			reverse := false
+			nulls := ""
 
 			if strings.HasPrefix(field, "-") {
 				field = field[1:]
 				reverse = true
 			}
 
+			if i := strings.Index(field, ":"); i >= 0 {
+				nulls = field[i+1:]
+				field = field[:i]
+			}
+
 			query.Sort = append(query.Sort, Sort{
 				Field:   field,
 				Reverse: reverse,
+				Nulls:   nulls,
 			})
 		}
 	}
 
+	if search := params.Get("q"); search != "" {
+		query.Search = search
+	}
+
 	if limit := params.Get("limit"); limit != "" {
 		var err error
 
@@ -90,3 +169,45 @@ func FromURLValues(params url.Values) (Query, error) {
 
 	return query, nil
 }
+
+// splitList splits each value by the list separator, honoring backslash escapes.
+func (p Parser) splitList(values []string) []string {
+	result := []string{}
+
+	for _, value := range values {
+		var item strings.Builder
+
+		for i := 0; i < len(value); i++ {
+			switch {
+			case strings.HasPrefix(value[i:], `\\`):
+				item.WriteByte('\\')
+				i++
+			case strings.HasPrefix(value[i:], `\`+p.ListSeparator):
+				item.WriteString(p.ListSeparator)
+				i += len(p.ListSeparator)
+			case strings.HasPrefix(value[i:], p.ListSeparator):
+				result = append(result, item.String())
+				item.Reset()
+				i += len(p.ListSeparator) - 1
+			default:
+				item.WriteByte(value[i])
+			}
+		}
+
+		result = append(result, item.String())
+	}
+
+	return result
+}
+
+// preloadParam returns the preload of with the key belongs to, such as comments for
+// comments.limit, or an empty string if the key belongs to no preload.
+func preloadParam(with []string, key string) string {
+	for _, preload := range with {
+		if strings.HasPrefix(key, preload+".") {
+			return preload
+		}
+	}
+
+	return ""
+}
@@ -23,6 +23,14 @@ func TestFromQueryString(t *testing.T) {
 				},
 			},
 		},
+		{
+			query: "field1_notnull=true",
+			out: Query{
+				Conditions: []Condition{
+					{"field1", "notnull", []string{"true"}},
+				},
+			},
+		},
 		{
 			query: "field1_gt=value1&field2_lt=value2&field3_gte=value3&field4_lte=value4",
 			out: Query{
@@ -54,12 +62,68 @@ func TestFromQueryString(t *testing.T) {
 				},
 			},
 		},
+		{
+			query: "field1_between=value1,value2&field2_nbetween=value3&field2_nbetween=value4",
+			out: Query{
+				Conditions: []Condition{
+					{"field1", "between", []string{"value1", "value2"}},
+					{"field2", "nbetween", []string{"value3", "value4"}},
+				},
+			},
+		},
+		{
+			query: "field1_gt=10&field1_gt=20&field2_in=value1&field2_in=value2",
+			out: Query{
+				Conditions: []Condition{
+					{"field1", "gt", []string{"10"}},
+					{"field1", "gt", []string{"20"}},
+					{"field2", "in", []string{"value1", "value2"}},
+				},
+			},
+		},
+		{
+			query: `field1_in=value1,value2&field2_nin=value3\,value4,value5\\&field3_eq=value6,value7`,
+			out: Query{
+				Conditions: []Condition{
+					{"field1", "in", []string{"value1", "value2"}},
+					{"field2", "nin", []string{"value3,value4", "value5\\"}},
+					{"field3", "eq", []string{"value6,value7"}},
+				},
+			},
+		},
+		{
+			query: "field1_has=value1&field1_hasany=value2,value3&field_1_lengte=2",
+			out: Query{
+				Conditions: []Condition{
+					{"field1", "has", []string{"value1"}},
+					{"field1", "hasany", []string{"value2", "value3"}},
+					{"field_1", "lengte", []string{"2"}},
+				},
+			},
+		},
+		{
+			query: "cable_len_gt=3",
+			out: Query{
+				Conditions: []Condition{
+					{"cable_len", "gt", []string{"3"}},
+				},
+			},
+		},
 		{
 			query: "sort=-field1&sort=field2",
 			out: Query{
 				Sort: []Sort{
-					{"field1", true},
-					{"field2", false},
+					{Field: "field1", Reverse: true},
+					{Field: "field2", Reverse: false},
+				},
+			},
+		},
+		{
+			query: "sort=-field1:nullslast&sort=field2:nullsfirst",
+			out: Query{
+				Sort: []Sort{
+					{Field: "field1", Reverse: true, Nulls: NullsLast},
+					{Field: "field2", Reverse: false, Nulls: NullsFirst},
 				},
 			},
 		},
@@ -69,6 +133,28 @@ func TestFromQueryString(t *testing.T) {
 				With: []string{"field1", "field2"},
 			},
 		},
+		{
+			query: "with=comments&comments.sort=-created_at&comments.limit=5&comments.author_eq=me&comments.with=likes&comments.likes.limit=1&tags.name_eq=go&other.limit=1",
+			out: Query{
+				Conditions: []Condition{
+					{"tags.name", "eq", []string{"go"}},
+				},
+				With: []string{"comments"},
+				WithQuery: map[string]Query{
+					"comments": {
+						Conditions: []Condition{
+							{"author", "eq", []string{"me"}},
+						},
+						With: []string{"likes"},
+						WithQuery: map[string]Query{
+							"likes": {Limit: 1},
+						},
+						Sort:  []Sort{{Field: "created_at", Reverse: true}},
+						Limit: 5,
+					},
+				},
+			},
+		},
 		{
 			query: "group=field1&group=field2",
 			out: Query{
@@ -81,6 +167,12 @@ func TestFromQueryString(t *testing.T) {
 				Accumulator: []string{"field1", "field2"},
 			},
 		},
+		{
+			query: "q=hello+world",
+			out: Query{
+				Search: "hello world",
+			},
+		},
 		{
 			query: "limit=200&skip=20",
 			out: Query{
@@ -96,6 +188,48 @@ func TestFromQueryString(t *testing.T) {
 
 			assert.NoError(t, err, "error should be nil")
 			assert.ElementsMatch(t, scenario.out.Conditions, out.Conditions, "conditions should match")
+			assert.Equal(t, scenario.out.Search, out.Search, "search should be equal")
+			assert.Equal(t, scenario.out.Sort, out.Sort, "sort should be equal")
+			assert.Equal(t, scenario.out.With, out.With, "with should be equal")
+			assert.Equal(t, scenario.out.WithQuery, out.WithQuery, "with query should be equal")
+			assert.Equal(t, scenario.out.Group, out.Group, "group should be equal")
+			assert.Equal(t, scenario.out.Accumulator, out.Accumulator, "accumulator should be equal")
+			assert.Equal(t, scenario.out.Limit, out.Limit, "limit should be equal")
+			assert.Equal(t, scenario.out.Skip, out.Skip, "skip should be equal")
+		})
+	}
+}
+
+func TestParserListSeparator(t *testing.T) {
+	type scenarioT struct {
+		parser Parser
+		query  string
+		out    []Condition
+	}
+
+	scenarios := []scenarioT{
+		{
+			parser: Parser{},
+			query:  "field1_in=value1,value2",
+			out: []Condition{
+				{"field1", "in", []string{"value1,value2"}},
+			},
+		},
+		{
+			parser: Parser{ListSeparator: "|"},
+			query:  "field1_in=value1|value2,value3&field1_in=value4",
+			out: []Condition{
+				{"field1", "in", []string{"value1", "value2,value3", "value4"}},
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.query, func(t *testing.T) {
+			out, err := scenario.parser.FromQueryString(scenario.query)
+
+			assert.NoError(t, err, "error should be nil")
+			assert.ElementsMatch(t, scenario.out, out.Conditions, "conditions should match")
 		})
 	}
 }